@@ -1,3 +0,0 @@
-module Day1
-
-go 1.15
@@ -1,3 +0,0 @@
-module Day10
-
-go 1.15
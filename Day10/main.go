@@ -1,41 +1,49 @@
-package main
+package day10
 
 import (
-    "bufio"
-    "fmt"
-    "os"
+    "errors"
+    "io"
     "sort"
-    "strconv"
+
+    "AdventOfCode20/aoc"
+    "AdventOfCode20/input"
 )
 
-func main() {
+func init() {
+    aoc.Register(10, func() aoc.Solver { return &solver{} })
+}
 
-    workingDir, err := os.Getwd()
-    if err != nil {
-        panic(fmt.Sprintf("Could not establish working directory, error: %v", err))
-    }
+type solver struct {
+    ratings []int
+}
 
-    ratings, err := loadRatings(workingDir + "\\input")
+// Ratings are sorted and completed with the rating for the end device which is 3 jolts higher than the most powerful
+// adapter.
+func (s *solver) Parse(reader io.Reader, onError input.ErrorHandler) error {
+    ratings, err := input.Ints(reader, onError)
     if err != nil {
-        panic(fmt.Sprintf("Could not load input file, error: %v\n", err))
+        return err
+    }
+    if len(ratings) == 0 {
+        return errors.New("no adapter ratings found in input")
     }
-    sort.Ints(ratings)
 
-    // PART 1 ----->
+    sort.Ints(ratings)
+    s.ratings = append(ratings, ratings[len(ratings)-1]+3)
+    return nil
+}
 
-    // Add the rating for the end device which is 3 jolts higher than the most powerful adapter.
-    ratings = append(ratings, ratings[len(ratings)-1]+3)
-    if oneHops, threeHops, ok := countIncreasesInAdapterSequence(ratings); ok {
-        fmt.Printf("PART 1: Number of 1-hops multiplied by 3-hops is: %d\n", oneHops*threeHops)
+func (s *solver) Part1() (aoc.Answer, error) {
+    if oneHops, threeHops, ok := countIncreasesInAdapterSequence(s.ratings); ok {
+        return aoc.Answer(oneHops * threeHops), nil
     }
+    return 0, aoc.ErrNoAnswer
+}
 
-    // PART 1 ----->
-
+func (s *solver) Part2() (aoc.Answer, error) {
     // Prepend ratings with initial value of outlet (which is 0).
-    ratings = append([]int{0}, ratings...)
-    sequenceCount := countAdapterSequences(ratings)
-    fmt.Printf("PART 2: Number of possible adapter sequences is: %d\n", sequenceCount)
-
+    ratings := append([]int{0}, s.ratings...)
+    return aoc.Answer(countAdapterSequences(ratings)), nil
 }
 
 // Counts number of increases by 1 and by 3 in sorted integer slice. Example: in slice of [1,3,4,7,10] the number of
@@ -90,25 +98,3 @@ func countAdapterSequences(ratings []int) int {
 
     return options[0]
 }
-
-// Loads file rows into slice of integers.
-// Non-numeric rows are logged and skipped.
-func loadRatings(filePath string) ([]int, error) {
-    file, err := os.Open(filePath)
-    if err != nil {
-        return nil, err
-    }
-    defer file.Close()
-
-    var ratings []int
-    scanner := bufio.NewScanner(file)
-    for scanner.Scan() {
-        record, err := strconv.Atoi(scanner.Text())
-        if err == nil {
-            ratings = append(ratings, record)
-        } else {
-            fmt.Printf("skipping non-numeric input line %q\n", scanner.Text())
-        }
-    }
-    return ratings, scanner.Err()
-}
@@ -1,3 +0,0 @@
-module Day11
-
-go 1.15
@@ -1,74 +1,68 @@
-package main
+package day11
 
 import (
-    "bufio"
     "fmt"
-    "os"
-)
+    "io"
 
-func main() {
+    "AdventOfCode20/aoc"
+    "AdventOfCode20/grid"
+    "AdventOfCode20/input"
+)
 
-    workingDir, err := os.Getwd()
-    if err != nil {
-        panic(fmt.Sprintf("Could not establish working directory, error: %v", err))
-    }
+func init() {
+    aoc.Register(11, func() aoc.Solver { return &solver{} })
+}
 
-    // PART 1 ----->
+type solver struct {
+    seatingMap seatingMap
+}
 
-    seatingMap, err := loadSeatingMap(workingDir + "\\input")
-    if err != nil {
-        panic(fmt.Sprintf("Could not load input file, error: %v\n", err))
-    }
+func (s *solver) Parse(reader io.Reader, onError input.ErrorHandler) error {
+    seatingMap, err := loadSeatingMap(reader, onError)
+    s.seatingMap = seatingMap
+    return err
+}
 
+func (s *solver) Part1() (aoc.Answer, error) {
+    seatingMap := s.seatingMap.clone()
     for {
         changed := seatingMap.runSimulation()
         if !changed {
             break
         }
     }
-    fmt.Printf("PART 1: Number of taken seats is: %d\n", seatingMap.countTakenSeats())
-
-    // PART 2 ----->
-
-    seatingMap, err = loadSeatingMap(workingDir + "\\input")
-    if err != nil {
-        panic(fmt.Sprintf("Could not load input file, error: %v\n", err))
-    }
+    return aoc.Answer(seatingMap.countTakenSeats()), nil
+}
 
+func (s *solver) Part2() (aoc.Answer, error) {
+    seatingMap := s.seatingMap.clone()
     for {
         changed := seatingMap.runSimulationWithVectors()
         if !changed {
             break
         }
     }
-    fmt.Printf("PART 2: Number of taken seats is: %d\n", seatingMap.countTakenSeats())
+    return aoc.Answer(seatingMap.countTakenSeats()), nil
 }
 
 const (
-    TakenSeat = '#'
-    FreeSeat  = 'L'
+    TakenSeat object = '#'
+    FreeSeat  object = 'L'
+    Floor     object = '.'
 )
 
-type coordinate struct {
-    x int
-    y int
-}
-
-type object struct {
-    currentState int32
-    nextState    int32
-}
+type object rune
 
 func (o object) isSeat() bool {
-    return o.currentState == TakenSeat || o.currentState == FreeSeat
+    return o == TakenSeat || o == FreeSeat
 }
 
 func (o object) isTakenSeat() bool {
-    return o.currentState == TakenSeat
+    return o == TakenSeat
 }
 
 type seatingMap struct {
-    objects map[coordinate]*object
+    objects *grid.Grid[object]
 }
 
 // Simulates one step of seating distribution which is always based on the current state of seating plan.
@@ -76,27 +70,7 @@ type seatingMap struct {
 // - seat gets occupied when there is no adjacent seat taken
 // - seat is freed when there are 4 or more adjacent seats taken
 func (sm *seatingMap) runSimulation() bool {
-    hasStateChanged := false
-    for objPosition, obj := range sm.objects {
-        switch {
-        case !obj.isSeat():
-            obj.nextState = obj.currentState
-            break
-        case sm.countAdjacentSeatsTaken(objPosition) == 0:
-            obj.nextState = TakenSeat
-            break
-        case sm.countAdjacentSeatsTaken(objPosition) >= 4:
-            obj.nextState = FreeSeat
-            break
-        default:
-            obj.nextState = obj.currentState
-        }
-        if obj.currentState != obj.nextState {
-            hasStateChanged = true
-        }
-    }
-    sm.syncState()
-    return hasStateChanged
+    return sm.simulateStep(sm.countAdjacentSeatsTaken, 4)
 }
 
 // Simulates one step of seating distribution which is always based on the current state of seating plan.
@@ -105,83 +79,65 @@ func (sm *seatingMap) runSimulation() bool {
 // - seat is freed when there are 5 or more taken seats in sight
 // - seat in sight means one that's located in base perpendicular axis or their 45-degree rotation
 func (sm *seatingMap) runSimulationWithVectors() bool {
+    return sm.simulateStep(sm.countVisibleSeatsTaken, 5)
+}
+
+// Shared simulation step of both rule sets. Seat gets occupied when the counting function finds no taken seat and is
+// freed once the count reaches given tolerance. Next state is prepared in a separate grid, so every decision is based
+// on the current state only.
+func (sm *seatingMap) simulateStep(countTaken func(p grid.Point) int, tolerance int) bool {
     hasStateChanged := false
-    for objPosition, obj := range sm.objects {
+    nextState := sm.objects.Clone()
+    sm.objects.Each(func(position grid.Point, obj object) {
+        if !obj.isSeat() {
+            return
+        }
+
+        takenCount := countTaken(position)
         switch {
-        case !obj.isSeat():
-            obj.nextState = obj.currentState
-            break
-        case sm.countVisibleSeatsTaken(objPosition) == 0:
-            obj.nextState = TakenSeat
-            break
-        case sm.countVisibleSeatsTaken(objPosition) >= 5:
-            obj.nextState = FreeSeat
-            break
-        default:
-            obj.nextState = obj.currentState
+        case takenCount == 0:
+            nextState.Set(position, TakenSeat)
+        case takenCount >= tolerance:
+            nextState.Set(position, FreeSeat)
         }
-        if obj.currentState != obj.nextState {
+        if next, _ := nextState.Get(position); next != obj {
             hasStateChanged = true
         }
-    }
-    sm.syncState()
+    })
+    sm.objects = nextState
     return hasStateChanged
 }
 
-// Saves the new state of the seating configuration as current state for the next step.
-func (sm *seatingMap) syncState() {
-    for _, obj := range sm.objects {
-        obj.currentState = obj.nextState
-    }
+// Creates a copy of the seating plan, so the simulation can run without affecting the original.
+func (sm seatingMap) clone() seatingMap {
+    return seatingMap{objects: sm.objects.Clone()}
 }
 
 // Returns the number of taken seats in the whole seating plan.
 func (sm seatingMap) countTakenSeats() int {
-    takenCount := 0
-    for _, obj := range sm.objects {
-        if obj.isTakenSeat() {
-            takenCount++
-        }
-    }
-
-    return takenCount
+    return sm.objects.Count(object.isTakenSeat)
 }
 
 // Counts the number of taken seats that are directly in touch with given position (there are up to 8 neighbouring
 // points for each individual point).
-func (sm seatingMap) countAdjacentSeatsTaken(c coordinate) int {
-    // Listing of all adjacent points
-    toCheck := []coordinate{
-        {x: c.x-1, y: c.y-1},
-        {x: c.x, y: c.y-1},
-        {x: c.x+1, y: c.y-1},
-        {x: c.x-1, y: c.y},
-        {x: c.x+1, y: c.y},
-        {x: c.x-1, y: c.y+1},
-        {x: c.x, y: c.y+1},
-        {x: c.x+1, y: c.y+1},
-    }
-
+func (sm seatingMap) countAdjacentSeatsTaken(p grid.Point) int {
     takenSeatCount := 0
-    for _, position := range toCheck {
-        // It doesn't matter if there is nothing on given coordinates (over the plan edge)
-        if obj, ok := sm.objects[position]; ok && obj.isTakenSeat() {
+    // It doesn't matter if there is nothing on given coordinates (over the plan edge)
+    sm.objects.EachNeighbour(p, grid.AllDirections, func(_ grid.Point, obj object) {
+        if obj.isTakenSeat() {
             takenSeatCount++
         }
-    }
+    })
 
     return takenSeatCount
 }
 
 // Counts the number of taken seats that are visible from given position. Visibility is possible in horizontal and
 // vertical axis and their 45-degree rotation. Any object except the floor block the further view.
-func (sm seatingMap) countVisibleSeatsTaken(c coordinate) int {
-    // Listing of all 45-degree rotations of base vector <0,1>
-    vectors := [][2]int{{0,1},{1,1},{1,0},{1,-1},{0,-1},{-1,-1},{-1,0},{-1,1}}
-
+func (sm seatingMap) countVisibleSeatsTaken(p grid.Point) int {
     takenSeatCount := 0
-    for _, vector := range vectors {
-        if obj, ok := sm.getVisibleObject(c, vector); ok && obj.isTakenSeat() {
+    for _, vector := range grid.AllDirections {
+        if _, obj, ok := sm.objects.Cast(p, vector, object.isSeat); ok && obj.isTakenSeat() {
             takenSeatCount++
         }
     }
@@ -189,42 +145,18 @@ func (sm seatingMap) countVisibleSeatsTaken(c coordinate) int {
     return takenSeatCount
 }
 
-// Retrieves the first visible object in sight. Visibility is possible in horizontal and vertical axis and their
-// 45-degree rotation.
-func (sm seatingMap) getVisibleObject(c coordinate, vector [2]int) (*object, bool) {
-    currentPosition := coordinate{x: c.x + vector[0], y: c.y + vector[1]}
-    for {
-        if obj, ok := sm.objects[currentPosition]; ok {
-            if obj.isSeat() {
-                return obj, true
-            }
-        } else {
-            return nil, false
+// Loads file rows into seating plan of seats and floor. Rows with other characters are reported to the error handler.
+func loadSeatingMap(reader io.Reader, onError input.ErrorHandler) (seatingMap, error) {
+    objects, err := grid.Decode(reader, grid.Bounded, func(char rune) (object, error) {
+        switch object(char) {
+        case TakenSeat, FreeSeat, Floor:
+            return object(char), nil
         }
-
-        currentPosition = coordinate{x: currentPosition.x + vector[0], y: currentPosition.y + vector[1]}
-    }
-}
-
-// Loads file rows into slice of integers.
-// Non-numeric rows are logged and skipped.
-func loadSeatingMap(filePath string) (seatingMap, error) {
-    file, err := os.Open(filePath)
+        return 0, fmt.Errorf("unknown object %q", char)
+    }, onError)
     if err != nil {
         return seatingMap{}, err
     }
-    defer file.Close()
-
-    var seatingMap seatingMap
-    seatingMap.objects = make(map[coordinate]*object)
-    yPos := 0
-    scanner := bufio.NewScanner(file)
-    for scanner.Scan() {
-        for xPos, letter := range scanner.Text() {
-            seatingMap.objects[coordinate{x: xPos, y: yPos}] = &object{currentState: letter}
-        }
-        yPos++
-    }
 
-    return seatingMap, scanner.Err()
+    return seatingMap{objects: objects}, nil
 }
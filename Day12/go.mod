@@ -1,3 +0,0 @@
-module Day12
-
-go 1.15
@@ -1,41 +1,46 @@
-package main
+package day12
 
 import (
-    "bufio"
-    "fmt"
+    "io"
     "math"
-    "os"
-    "strconv"
+
+    "AdventOfCode20/aoc"
+    "AdventOfCode20/input"
 )
 
-func main() {
+const InstructionRegex = `^(?P<action>[NSEWLRF])(?P<value>\d+)$`
 
-    workingDir, err := os.Getwd()
-    if err != nil {
-        panic(fmt.Sprintf("Could not establish working directory, error: %v", err))
-    }
+var instructionPattern = input.MustCompile(InstructionRegex)
 
-    instructions, err := loadInstructions(workingDir + "\\input")
-    if err != nil {
-        panic(fmt.Sprintf("Could not load input file, error: %v\n", err))
-    }
+func init() {
+    aoc.Register(12, func() aoc.Solver { return &solver{} })
+}
 
-    // PART 1 ----->
+type solver struct {
+    instructions []instruction
+}
 
+func (s *solver) Parse(reader io.Reader, onError input.ErrorHandler) error {
+    instructions, err := loadInstructions(reader, onError)
+    s.instructions = instructions
+    return err
+}
+
+func (s *solver) Part1() (aoc.Answer, error) {
     ferry := ferry{
-        program:   instructions,
+        program:   s.instructions,
         position:  position{0,0},
         direction: East,
     }
     ferry.setSail()
-    fmt.Printf("PART 1: Manhattan distance of the ferry is: %d\n", int(math.Abs(float64(ferry.position.ns)) + math.Abs(float64(ferry.position.ew))))
-
-    // PART 2 ----->
+    return aoc.Answer(ferry.position.manhattanDistance()), nil
+}
 
+func (s *solver) Part2() (aoc.Answer, error) {
     // Second calculation decides movement of 2 objects so it's not implemented as method of the ferry.
-    ferry.position = position{0, 0}
+    ferry := ferry{position: position{0, 0}}
     waypoint := position{1, 10}
-    for _, instruction := range instructions {
+    for _, instruction := range s.instructions {
         switch instruction.action {
         case 'N':
             waypoint.ns += instruction.value
@@ -53,8 +58,7 @@ func main() {
             ferry.moveToWaypoint(&waypoint, instruction.value)
         }
     }
-    fmt.Printf("PART 2: Manhattan distance of the ferry is: %d\n", int(math.Abs(float64(ferry.position.ns)) + math.Abs(float64(ferry.position.ew))))
-
+    return aoc.Answer(ferry.position.manhattanDistance()), nil
 }
 
 type direction int
@@ -70,6 +74,11 @@ type position struct {
     ew int
 }
 
+// Returns the sum of absolute distances from base [0,0] in both axes.
+func (p position) manhattanDistance() int {
+    return int(math.Abs(float64(p.ns)) + math.Abs(float64(p.ew)))
+}
+
 // Rotates point around base [0,0] by given amount of angle degrees.
 // Rotation is allowed clockwise (negative input) or counter-clockwise (positive input).
 func (p *position) rotate(degrees int) {
@@ -154,41 +163,28 @@ type instruction struct {
 }
 
 // Loads file rows into slice of instructions.
-// Invalid rows are logged and skipped.
-func loadInstructions(filePath string) ([]instruction, error) {
-    file, err := os.Open(filePath)
-    if err != nil {
-        return nil, err
-    }
-    defer file.Close()
-
+// Invalid rows are reported to the error handler.
+func loadInstructions(reader io.Reader, onError input.ErrorHandler) ([]instruction, error) {
     var instructions []instruction
-    scanner := bufio.NewScanner(file)
-    for scanner.Scan() {
-        if inst, ok := parseInstruction(scanner.Text()); ok {
-            instructions = append(instructions, inst)
-        } else {
-            fmt.Printf("skipping invalid input line %q\n", scanner.Text())
+    err := input.EachLine(reader, func(line input.Line) error {
+        inst, err := parseInstruction(line)
+        if err != nil {
+            return onError(err)
         }
-    }
-    return instructions, scanner.Err()
+        instructions = append(instructions, inst)
+        return nil
+    })
+    return instructions, err
 }
 
-func parseInstruction(data string) (instruction, bool) {
-    allowedValues := map[uint8]bool{
-        'N': true, 'S': true, 'E': true, 'W': true, 'L': true, 'R': true, 'F': true,
+func parseInstruction(line input.Line) (instruction, error) {
+    var data struct {
+        Action uint8
+        Value  int
     }
-
-    var result instruction
-    if _, ok := allowedValues[data[0]]; ok {
-        result.action = data[0]
-
-        value, err := strconv.Atoi(data[1:])
-        if err == nil {
-            result.value = value
-            return result, true
-        }
+    if err := instructionPattern.Extract(line, &data); err != nil {
+        return instruction{}, err
     }
 
-    return result, false
+    return instruction{action: data.Action, value: data.Value}, nil
 }
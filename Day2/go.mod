@@ -1,3 +0,0 @@
-module Day2
-
-go 1.15
@@ -1,3 +0,0 @@
-module Day3
-
-go 1.15
@@ -1,3 +0,0 @@
-module Day4
-
-go 1.15
@@ -1,45 +1,49 @@
-package main
+package day4
 
 import (
-    "bufio"
     "fmt"
-    "os"
+    "io"
     "reflect"
     "regexp"
     "strconv"
     "strings"
+
+    "AdventOfCode20/aoc"
+    "AdventOfCode20/input"
 )
 
-func main() {
-    workingDir, err := os.Getwd()
-    if err != nil {
-        panic(fmt.Sprintf("Could not establish working directory, error: %v", err))
-    }
+func init() {
+    aoc.Register(4, func() aoc.Solver { return &solver{} })
+}
 
-    passports, err := loadPassports(workingDir + "\\input")
-    if err != nil {
-        panic(fmt.Sprintf("Could not load input file, error: %v\n", err))
-    }
+type solver struct {
+    passports []passport
+}
 
-    // PART 1 ----->
+func (s *solver) Parse(reader io.Reader, onError input.ErrorHandler) error {
+    passports, err := loadPassports(reader, onError)
+    s.passports = passports
+    return err
+}
 
+func (s *solver) Part1() (aoc.Answer, error) {
     numberOfValid := 0
-    for _, passport := range passports {
+    for _, passport := range s.passports {
         if passport.isDocumentValid() {
             numberOfValid++
         }
     }
-    fmt.Printf("PART 1: Found %d valid passports\n", numberOfValid)
-
-    // PART 2 ----->
+    return aoc.Answer(numberOfValid), nil
+}
 
-    numberOfValid = 0
-    for _, passport := range passports {
+func (s *solver) Part2() (aoc.Answer, error) {
+    numberOfValid := 0
+    for _, passport := range s.passports {
         if passport.areDocumentRecordsValid() {
             numberOfValid++
         }
     }
-    fmt.Printf("PART 2: Found %d valid passports\n", numberOfValid)
+    return aoc.Answer(numberOfValid), nil
 }
 
 type passport struct {
@@ -114,48 +118,56 @@ func validateAsNumberOfDigits(digits string, numberOfDigits int) bool {
 }
 
 // Loads file rows collection of Passport structures.
-// Invalid records within passport data feed are ignored.
-func loadPassports(filePath string) ([]passport, error) {
-    file, err := os.Open(filePath)
-    if err != nil {
-        return nil, err
-    }
-    defer file.Close()
-
+// Invalid records within passport data feed are reported to the error handler and skipped.
+func loadPassports(reader io.Reader, onError input.ErrorHandler) ([]passport, error) {
     var passports []passport
 
-    scanner := bufio.NewScanner(file)
-    var currentPassport passport
-    for scanner.Scan() {
-        // Passport record can span over multiple lines, but wholly empty line indicates new passport record.
-        if scanner.Text() == "" {
-            passports = append(passports, currentPassport)
-            currentPassport = passport{}
-        } else {
-            parsePassportData(scanner.Text(), &currentPassport)
+    // Passport record can span over multiple lines, but wholly empty line indicates new passport record.
+    err := input.EachParagraph(reader, func(lines []input.Line) error {
+        var currentPassport passport
+        for _, line := range lines {
+            if err := parsePassportData(line, &currentPassport, onError); err != nil {
+                return err
+            }
         }
-    }
-    passports = append(passports, currentPassport)
+        passports = append(passports, currentPassport)
+        return nil
+    })
 
-    return passports, scanner.Err()
+    return passports, err
 }
 
 // All passport properties are treated as string values. This parser uses reflection to map the property values from
-// data stream to the respective fields of Passport structure.
-func parsePassportData(data string, passport *passport) {
-    for _, datum := range strings.Split(data, " ") {
+// data stream to the respective fields of Passport structure. Malformed records and unknown properties are passed to
+// the error handler together with their column.
+func parsePassportData(line input.Line, passport *passport, onError input.ErrorHandler) error {
+    column := 1
+    for _, datum := range strings.Split(line.Text, " ") {
+        datumColumn := column
+        column += len(datum) + 1
+        if datum == "" {
+            continue
+        }
+
         tuple := strings.Split(datum, ":")
         if len(tuple) != 2 {
+            if err := onError(input.Errorf(line, datumColumn, "malformed record %q", datum)); err != nil {
+                return err
+            }
             continue
         }
 
         r := reflect.ValueOf(passport)
         s := r.Elem()
         f := s.FieldByName(strings.Title(tuple[0]))
-        if f.CanSet() {
-            if f.Kind() == reflect.String {
-                f.SetString(tuple[1])
+        if !f.CanSet() || f.Kind() != reflect.String {
+            if err := onError(input.Errorf(line, datumColumn, "unknown passport property %q", tuple[0])); err != nil {
+                return err
             }
+            continue
         }
+        f.SetString(tuple[1])
     }
+
+    return nil
 }
@@ -1,3 +0,0 @@
-module Day5
-
-go 1.15
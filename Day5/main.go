@@ -1,59 +1,66 @@
-package main
+package day5
 
 import (
-    "bufio"
     "fmt"
-    "os"
-    "regexp"
+    "io"
     "strconv"
+
+    "AdventOfCode20/aoc"
+    "AdventOfCode20/input"
 )
 
 const (
-    BoardingPassFormat = `^([F,B]{7})([L,R]{3})$`
+    BoardingPassFormat = `^(?P<row>[F,B]{7})(?P<column>[L,R]{3})$`
 )
 
-func main() {
-    workingDir, err := os.Getwd()
-    if err != nil {
-        panic(fmt.Sprintf("Could not establish working directory, error: %v", err))
-    }
+var boardingPassPattern = input.MustCompile(BoardingPassFormat)
+
+func init() {
+    aoc.Register(5, func() aoc.Solver { return &solver{} })
+}
 
-    passes, err := loadBoardingPasses(workingDir + "\\input")
+type solver struct {
+    highestSeatId   int
+    allTakenSeatIds map[int]bool
+}
+
+// Parsing also does the shared pre-calculation for both parts: finds the highest seat ID and collects the set of all
+// taken seat IDs.
+func (s *solver) Parse(reader io.Reader, onError input.ErrorHandler) error {
+    passes, err := loadBoardingPasses(reader, onError)
     if err != nil {
-        panic(fmt.Sprintf("Could not load input file, error: %v\n", err))
+        return err
     }
 
-    // SHARED PRE-CALCULATION FOR BOTH PARTS
-
-    highestSeatId := 0
-    allTakenSeatIds := make(map[int]bool)
+    s.allTakenSeatIds = make(map[int]bool)
     for _, pass := range passes {
         seatId := pass.getSeatId()
-        allTakenSeatIds[seatId] = true
-        if seatId > highestSeatId {
-            highestSeatId = seatId
+        s.allTakenSeatIds[seatId] = true
+        if seatId > s.highestSeatId {
+            s.highestSeatId = seatId
         }
     }
 
-    // PART 1 ----->
-
-    fmt.Printf("PART 1: Highest Seat ID is %d\n", highestSeatId)
+    return nil
+}
 
-    // PART 2 ----->
+func (s *solver) Part1() (aoc.Answer, error) {
+    return aoc.Answer(s.highestSeatId), nil
+}
 
-    mySeatId := 0
-    for seatId := 0; seatId < highestSeatId; seatId++ {
-        if _, ok := allTakenSeatIds[seatId]; ok {
+func (s *solver) Part2() (aoc.Answer, error) {
+    for seatId := 0; seatId < s.highestSeatId; seatId++ {
+        if _, ok := s.allTakenSeatIds[seatId]; ok {
             continue
         }
 
-        _, prevSeatTaken := allTakenSeatIds[seatId - 1]
-        _, nextSeatTaken := allTakenSeatIds[seatId + 1]
+        _, prevSeatTaken := s.allTakenSeatIds[seatId - 1]
+        _, nextSeatTaken := s.allTakenSeatIds[seatId + 1]
         if prevSeatTaken && nextSeatTaken {
-            mySeatId = seatId
+            return aoc.Answer(seatId), nil
         }
     }
-    fmt.Printf("PART 2: My Seat ID is %d\n", mySeatId)
+    return 0, aoc.ErrNoAnswer
 }
 
 type boardingPass struct {
@@ -108,30 +115,25 @@ func turnBitStringToNumber(value string, zeroBitLetter, oneBitLetter int32) (int
     return int(rowIndex), nil
 }
 
-// Loads file rows into slice password records.
-// Rows that do not match password record format (enforced by regex) are skipped.
-func loadBoardingPasses(filePath string) ([]boardingPass, error) {
-    file, err := os.Open(filePath)
-    if err != nil {
-        return nil, err
-    }
-    defer file.Close()
-
+// Loads file rows into slice of boarding passes.
+// Rows that do not match boarding pass format (enforced by regex) are reported to the error handler.
+func loadBoardingPasses(reader io.Reader, onError input.ErrorHandler) ([]boardingPass, error) {
     var passes []boardingPass
-    scanner := bufio.NewScanner(file)
-    for scanner.Scan() {
-        recordRegex := regexp.MustCompile(BoardingPassFormat)
-        if !recordRegex.MatchString(scanner.Text()) {
-            fmt.Printf("skipping invalid input line %q\n", scanner.Text())
-            continue
+    err := input.EachLine(reader, func(line input.Line) error {
+        var passParts struct {
+            Row    string
+            Column string
+        }
+        if err := boardingPassPattern.Extract(line, &passParts); err != nil {
+            return onError(err)
         }
 
-        passParts := recordRegex.FindStringSubmatch(scanner.Text())
         passes = append(passes, boardingPass{
-            rowCode:    passParts[1],
-            columnCode: passParts[2],
+            rowCode:    passParts.Row,
+            columnCode: passParts.Column,
         })
-    }
+        return nil
+    })
 
-    return passes, scanner.Err()
+    return passes, err
 }
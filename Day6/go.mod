@@ -1,3 +0,0 @@
-module Day6
-
-go 1.15
@@ -1,37 +1,40 @@
-package main
+package day6
 
 import (
-    "bufio"
-    "fmt"
-    "os"
+    "io"
+
+    "AdventOfCode20/aoc"
+    "AdventOfCode20/input"
 )
 
-func main() {
-    workingDir, err := os.Getwd()
-    if err != nil {
-        panic(fmt.Sprintf("Could not establish working directory, error: %v", err))
-    }
+func init() {
+    aoc.Register(6, func() aoc.Solver { return &solver{} })
+}
 
-    answerGroups, err := loadAnswers(workingDir + "\\input")
-    if err != nil {
-        panic(fmt.Sprintf("Could not load input file, error: %v\n", err))
-    }
+type solver struct {
+    answerGroups []answerGroup
+}
 
-    // PART 1 ----->
+func (s *solver) Parse(reader io.Reader, onError input.ErrorHandler) error {
+    answerGroups, err := loadAnswers(reader, onError)
+    s.answerGroups = answerGroups
+    return err
+}
 
+func (s *solver) Part1() (aoc.Answer, error) {
     answerSum := 0
-    for _, group := range answerGroups {
+    for _, group := range s.answerGroups {
         answerSum += len(group.uniqueAnswers)
     }
-    fmt.Printf("PART 1: Found %d unique answers\n", answerSum)
-
-    // PART 2 ----->
+    return aoc.Answer(answerSum), nil
+}
 
+func (s *solver) Part2() (aoc.Answer, error) {
     commonAnswerSum := 0
-    for _, group := range answerGroups {
+    for _, group := range s.answerGroups {
         commonAnswerSum += group.getCommonAnswerCount()
     }
-    fmt.Printf("PART 2: Found %d unique answers common to all respondents\n", commonAnswerSum)
+    return aoc.Answer(commonAnswerSum), nil
 }
 
 type answerGroup struct {
@@ -58,37 +61,41 @@ func (ag answerGroup) getCommonAnswerCount() int {
 
 // Loads questionnaire (input) answers and serializes them into groups (separated by blank lines). Answers themselves
 // are also categorized per person which is represented by a new line. Answer is identified any represented by single letter.
-func loadAnswers(filePath string) ([]answerGroup, error) {
-    file, err := os.Open(filePath)
-    if err != nil {
-        return nil, err
-    }
-    defer file.Close()
-
+// Lines with other characters than letters of questions are reported to the error handler.
+func loadAnswers(reader io.Reader, onError input.ErrorHandler) ([]answerGroup, error) {
     var answerGroups []answerGroup
 
-    scanner := bufio.NewScanner(file)
-    var currentAnswerGroup answerGroup
-    for scanner.Scan() {
-        // Answers can span over multiple lines, but wholly empty line indicates new group.
-        if scanner.Text() == "" {
+    // Answers can span over multiple lines, but wholly empty line indicates new group.
+    err := input.EachParagraph(reader, func(lines []input.Line) error {
+        var currentAnswerGroup answerGroup
+        for _, line := range lines {
+            if err := parseAnswers(line, &currentAnswerGroup); err != nil {
+                if err := onError(err); err != nil {
+                    return err
+                }
+            }
+        }
+        // Group of skipped lines only has no person to count answers of.
+        if len(currentAnswerGroup.personAnswers) > 0 {
             answerGroups = append(answerGroups, currentAnswerGroup)
-            currentAnswerGroup = answerGroup{}
-        } else {
-            parseAnswers(scanner.Text(), &currentAnswerGroup)
         }
-    }
-    answerGroups = append(answerGroups, currentAnswerGroup)
+        return nil
+    })
 
-    return answerGroups, scanner.Err()
+    return answerGroups, err
 }
 
 // We need to parse answer data per person, but we can also get set of unique answers (for the whole data input) right
 // away (which is useful for getting first output).
-func parseAnswers(data string, answerGroup *answerGroup) {
-    answers := make(map[int32]bool)
+func parseAnswers(line input.Line, answerGroup *answerGroup) error {
+    for column, letter := range []rune(line.Text) {
+        if letter < 'a' || letter > 'z' {
+            return input.Errorf(line, column+1, "answer %q is not a question letter", letter)
+        }
+    }
 
-    for _, letter := range data {
+    answers := make(map[int32]bool)
+    for _, letter := range line.Text {
         if answerGroup.uniqueAnswers == nil {
             answerGroup.uniqueAnswers = make(map[int32]bool)
         }
@@ -98,4 +105,5 @@ func parseAnswers(data string, answerGroup *answerGroup) {
     }
 
     answerGroup.personAnswers = append(answerGroup.personAnswers, answers)
+    return nil
 }
@@ -1,3 +0,0 @@
-module Day7
-
-go 1.15
@@ -1,40 +1,46 @@
-package main
+package day7
 
 import (
-    "bufio"
-    "fmt"
-    "os"
-    "regexp"
-    "strconv"
+    "io"
+
+    "AdventOfCode20/aoc"
+    "AdventOfCode20/input"
 )
 
 const (
-    BagColorRegex = `^([a-z]+\s[a-z]+)\sbags\scontain.*$`
+    BagColorRegex = `^(?P<color>[a-z]+\s[a-z]+)\sbags\scontain.*$`
     InnerBagsRegex = `((?P<count>\d)\s(?P<color>[a-z]+\s[a-z]+)\sbag[s]?)`
 )
 
-func main() {
-    workingDir, err := os.Getwd()
-    if err != nil {
-        panic(fmt.Sprintf("Could not establish working directory, error: %v", err))
-    }
+var (
+    bagColorPattern  = input.MustCompile(BagColorRegex)
+    innerBagsPattern = input.MustCompile(InnerBagsRegex)
+)
 
-    baggageRules, err := loadBaggageRules(workingDir + "\\input")
-    if err != nil {
-        panic(fmt.Sprintf("Could not load input file, error: %v\n", err))
-    }
+func init() {
+    aoc.Register(7, func() aoc.Solver { return &solver{} })
+}
 
-    // PART 1 ----->
+type solver struct {
+    baggageRules []baggageRule
+}
 
-    allowedBags := make(map[string]bool)
-    findBagsThatCanContain(baggageRules, "shiny gold", allowedBags)
-    fmt.Printf("PART 1: Shiny Gold bag can be contained in %d bag types\n", len(allowedBags))
+func (s *solver) Parse(reader io.Reader, onError input.ErrorHandler) error {
+    baggageRules, err := loadBaggageRules(reader, onError)
+    s.baggageRules = baggageRules
+    return err
+}
 
-    // PART 2 ----->
+func (s *solver) Part1() (aoc.Answer, error) {
+    allowedBags := make(map[string]bool)
+    findBagsThatCanContain(s.baggageRules, "shiny gold", allowedBags)
+    return aoc.Answer(len(allowedBags)), nil
+}
 
+func (s *solver) Part2() (aoc.Answer, error) {
     numberOfBagsInside := 0
-    findNumberOfBagsContainedIn(baggageRules, "shiny gold", 1, &numberOfBagsInside)
-    fmt.Printf("PART 2: Shiny Gold bag contains %d other bags\n", numberOfBagsInside)
+    findNumberOfBagsContainedIn(s.baggageRules, "shiny gold", 1, &numberOfBagsInside)
+    return aoc.Answer(numberOfBagsInside), nil
 }
 
 // Recursively searches through available baggage rules and find color of those bags that can (even indirectly) contain
@@ -82,48 +88,46 @@ func (br baggageRule) canContainBag(color string) bool {
     return ok
 }
 
-// Loads baggage rules from input file. Each file line represents individual rule.
-func loadBaggageRules(filePath string) ([]baggageRule, error) {
-    file, err := os.Open(filePath)
-    if err != nil {
-        return nil, err
-    }
-    defer file.Close()
-
+// Loads baggage rules from input file. Each file line represents individual rule, lines which are not rules are
+// reported to the error handler.
+func loadBaggageRules(reader io.Reader, onError input.ErrorHandler) ([]baggageRule, error) {
     var baggageRules []baggageRule
 
-    scanner := bufio.NewScanner(file)
-    for scanner.Scan() {
-        if parsedRule, ok := parseBaggageRule(scanner.Text()); ok {
-            baggageRules = append(baggageRules, parsedRule)
+    err := input.EachLine(reader, func(line input.Line) error {
+        parsedRule, err := parseBaggageRule(line)
+        if err != nil {
+            return onError(err)
         }
-    }
+        baggageRules = append(baggageRules, parsedRule)
+        return nil
+    })
 
-    return baggageRules, scanner.Err()
+    return baggageRules, err
 }
 
 // Each baggage rule is given in predefined format which is parsed using regular expressions.
 // Parser fist looks for base bag color and if it was found, it parses the rules about bags it contains (those are
 // composed of bag color and count).
-func parseBaggageRule(data string) (baggageRule, bool) {
-    var rule baggageRule
+func parseBaggageRule(line input.Line) (baggageRule, error) {
+    var baseBag struct {
+        Color string
+    }
+    if err := bagColorPattern.Extract(line, &baseBag); err != nil {
+        return baggageRule{}, err
+    }
 
-    baseColorRegex := regexp.MustCompile(BagColorRegex)
-    baseColorParts := baseColorRegex.FindStringSubmatch(data)
-    if len(baseColorParts) < 2 {
-        return baggageRule{}, false
+    var innerBags []struct {
+        Count int
+        Color string
     }
-    rule.color = baseColorParts[1]
-
-    rulesRegex := regexp.MustCompile(InnerBagsRegex)
-    rule.canContain = make(map[string]int)
-    rulesData := rulesRegex.FindAllStringSubmatch(data, -1)
-    for _, ruleData := range rulesData {
-        bagCount, err := strconv.Atoi(ruleData[2])
-        if err == nil {
-            rule.canContain[ruleData[3]] = bagCount
-        }
+    if err := innerBagsPattern.ExtractAll(line, &innerBags); err != nil {
+        return baggageRule{}, err
+    }
+
+    rule := baggageRule{color: baseBag.Color, canContain: make(map[string]int)}
+    for _, innerBag := range innerBags {
+        rule.canContain[innerBag.Color] = innerBag.Count
     }
 
-    return rule, true
+    return rule, nil
 }
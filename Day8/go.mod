@@ -1,3 +0,0 @@
-module Day8
-
-go 1.15
@@ -1,3 +0,0 @@
-module Day9
-
-go 1.15
@@ -1,36 +1,48 @@
-package main
+package day9
 
 import (
-    "bufio"
-    "fmt"
-    "os"
+    "io"
     "sort"
-    "strconv"
+
+    "AdventOfCode20/aoc"
+    "AdventOfCode20/input"
 )
 
-func main() {
-    workingDir, err := os.Getwd()
-    if err != nil {
-        panic(fmt.Sprintf("Could not establish working directory, error: %v", err))
-    }
+func init() {
+    aoc.Register(9, func() aoc.Solver { return &solver{preamble: 25} })
+}
 
-    numbers, err := loadInput(workingDir + "\\input")
-    if err != nil {
-        panic(fmt.Sprintf("Could not load input file, error: %v\n", err))
-    }
+type solver struct {
+    preamble int
+    numbers  []int
+}
 
-    // PART 1 ----->
+func (s *solver) Parse(reader io.Reader, onError input.ErrorHandler) error {
+    numbers, err := input.Ints(reader, onError)
+    s.numbers = numbers
+    return err
+}
 
-    if firstError, ok := findFirstInvalidNumber(numbers, 25); ok {
-        fmt.Printf("PART 1: First number that doesn't pass the check is: %d\n", firstError)
+func (s *solver) Part1() (aoc.Answer, error) {
+    if firstError, ok := findFirstInvalidNumber(s.numbers, s.preamble); ok {
+        return aoc.Answer(firstError), nil
+    }
+    return 0, aoc.ErrNoAnswer
+}
 
-        // PART 2 ----->
+// Second part looks for the sequence adding up to the result of the first part.
+func (s *solver) Part2() (aoc.Answer, error) {
+    firstError, ok := findFirstInvalidNumber(s.numbers, s.preamble)
+    if !ok {
+        return 0, aoc.ErrNoAnswer
+    }
 
-        if sequence, ok := findContiguousListThatAddTo(numbers, firstError); ok {
-            sort.Ints(sequence)
-            fmt.Printf("PART 2: Sum of interval start and end is: %d\n", sequence[0]+sequence[len(sequence)-1])
-        }
+    if sequence, ok := findContiguousListThatAddTo(s.numbers, firstError); ok {
+        sorted := append([]int(nil), sequence...)
+        sort.Ints(sorted)
+        return aoc.Answer(sorted[0] + sorted[len(sorted)-1]), nil
     }
+    return 0, aoc.ErrNoAnswer
 }
 
 // Invalid number is one that cannot be represented by sum of any of 2 numbers from X (preamble param) numbers that
@@ -75,25 +87,3 @@ func findContiguousListThatAddTo(numbers []int, sum int) ([]int, bool) {
     }
     return []int{}, false
 }
-
-// Loads file rows into slice of integers.
-// Non-numeric rows are logged and skipped.
-func loadInput(filePath string) ([]int, error) {
-    file, err := os.Open(filePath)
-    if err != nil {
-        return nil, err
-    }
-    defer file.Close()
-
-    var records []int
-    scanner := bufio.NewScanner(file)
-    for scanner.Scan() {
-        record, err := strconv.Atoi(scanner.Text())
-        if err == nil {
-            records = append(records, record)
-        } else {
-            fmt.Printf("skipping non-numeric input line %q\n", scanner.Text())
-        }
-    }
-    return records, scanner.Err()
-}
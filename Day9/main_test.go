@@ -0,0 +1,39 @@
+package day9
+
+import (
+    "os"
+    "testing"
+
+    "AdventOfCode20/aoc"
+    "AdventOfCode20/input"
+)
+
+// Puzzle example uses preamble of 5 numbers instead of 25, so it cannot be covered by shared golden answers.
+func TestExampleWithShortPreamble(t *testing.T) {
+    file, err := os.Open("example")
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer file.Close()
+
+    s := &solver{preamble: 5}
+    if err := s.Parse(file, input.FailOnError); err != nil {
+        t.Fatal(err)
+    }
+
+    for part, tc := range []struct {
+        solve    func() (aoc.Answer, error)
+        expected aoc.Answer
+    }{
+        {s.Part1, 127},
+        {s.Part2, 62},
+    } {
+        answer, err := tc.solve()
+        if err != nil {
+            t.Fatalf("part %d: unexpected error: %v", part+1, err)
+        }
+        if answer != tc.expected {
+            t.Errorf("part %d: got %v, want %v", part+1, answer, tc.expected)
+        }
+    }
+}
@@ -0,0 +1,188 @@
+package aoc
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "os"
+    "runtime"
+    "time"
+
+    "AdventOfCode20/input"
+)
+
+// Phase names used in benchmarks and their comparisons.
+const (
+    PhaseParse = "parse"
+    PhasePart1 = "part 1"
+    PhasePart2 = "part 2"
+)
+
+// PhaseStats holds the average cost of one phase of solving a puzzle (per single iteration).
+type PhaseStats struct {
+    Duration time.Duration `json:"duration_ns"`
+    Allocs   uint64        `json:"allocs"`
+    Bytes    uint64        `json:"bytes"`
+}
+
+// Benchmark holds the average costs of parsing and solving both parts of a single day.
+type Benchmark struct {
+    Day        int        `json:"day"`
+    Iterations int        `json:"iterations"`
+    Parse      PhaseStats `json:"parse"`
+    Part1      PhaseStats `json:"part1"`
+    Part2      PhaseStats `json:"part2"`
+}
+
+// Phase returns statistics of the phase with given name.
+func (b Benchmark) Phase(name string) PhaseStats {
+    switch name {
+    case PhaseParse:
+        return b.Parse
+    case PhasePart1:
+        return b.Part1
+    default:
+        return b.Part2
+    }
+}
+
+// Bench repeatedly parses the input with a new solver of given day and solves both its parts. Every phase is measured
+// separately and the averages of all iterations are returned. Errors of the parts are ignored, as even failing search
+// for the answer takes its time. Invalid input lines are silently skipped, running the day reports them.
+func Bench(day int, data []byte, iterations int) (Benchmark, error) {
+    factory, ok := Lookup(day)
+    if !ok {
+        return Benchmark{}, fmt.Errorf("day %d has no registered solver", day)
+    }
+    if iterations < 1 {
+        iterations = 1
+    }
+
+    var parse, part1, part2 PhaseStats
+    for i := 0; i < iterations; i++ {
+        solver := factory()
+
+        var err error
+        measure(&parse, func() { err = solver.Parse(bytes.NewReader(data), input.Ignore) })
+        if err != nil {
+            return Benchmark{}, fmt.Errorf("day %d: could not parse input: %w", day, err)
+        }
+        measure(&part1, func() { _, _ = solver.Part1() })
+        measure(&part2, func() { _, _ = solver.Part2() })
+    }
+
+    return Benchmark{
+        Day:        day,
+        Iterations: iterations,
+        Parse:      parse.average(iterations),
+        Part1:      part1.average(iterations),
+        Part2:      part2.average(iterations),
+    }, nil
+}
+
+// Runs given function and adds its duration and allocations to the stats.
+func measure(stats *PhaseStats, f func()) {
+    var before, after runtime.MemStats
+    runtime.ReadMemStats(&before)
+    start := time.Now()
+
+    f()
+
+    elapsed := time.Since(start)
+    runtime.ReadMemStats(&after)
+
+    stats.Duration += elapsed
+    stats.Allocs += after.Mallocs - before.Mallocs
+    stats.Bytes += after.TotalAlloc - before.TotalAlloc
+}
+
+func (ps PhaseStats) average(iterations int) PhaseStats {
+    return PhaseStats{
+        Duration: ps.Duration / time.Duration(iterations),
+        Allocs:   ps.Allocs / uint64(iterations),
+        Bytes:    ps.Bytes / uint64(iterations),
+    }
+}
+
+// Regression describes a phase which got slower (or allocates more) than in the baseline by more than allowed.
+type Regression struct {
+    Day      int
+    Phase    string
+    Metric   string
+    Baseline float64
+    Current  float64
+}
+
+func (r Regression) String() string {
+    baseline, current := fmt.Sprintf("%.0f", r.Baseline), fmt.Sprintf("%.0f", r.Current)
+    if r.Metric == "duration" {
+        baseline, current = time.Duration(r.Baseline).String(), time.Duration(r.Current).String()
+    }
+
+    return fmt.Sprintf("day %d, %s: %s regressed from %s to %s (%+.1f%%)",
+        r.Day, r.Phase, r.Metric, baseline, current, (r.Current/r.Baseline-1)*100)
+}
+
+// CompareBenchmarks looks for regressions of current benchmarks against the baseline ones. Threshold is the allowed
+// relative increase (0.2 allows phase to be 20% slower). Days missing in baseline are not compared.
+func CompareBenchmarks(current, baseline []Benchmark, threshold float64) []Regression {
+    baselineByDay := make(map[int]Benchmark)
+    for _, b := range baseline {
+        baselineByDay[b.Day] = b
+    }
+
+    var regressions []Regression
+    for _, cur := range current {
+        base, ok := baselineByDay[cur.Day]
+        if !ok {
+            continue
+        }
+
+        for _, phase := range []string{PhaseParse, PhasePart1, PhasePart2} {
+            curStats, baseStats := cur.Phase(phase), base.Phase(phase)
+            metrics := []struct {
+                name              string
+                current, baseline float64
+            }{
+                {"duration", float64(curStats.Duration), float64(baseStats.Duration)},
+                {"allocations", float64(curStats.Allocs), float64(baseStats.Allocs)},
+            }
+            for _, m := range metrics {
+                if m.baseline > 0 && m.current > m.baseline*(1+threshold) {
+                    regressions = append(regressions, Regression{
+                        Day:      cur.Day,
+                        Phase:    phase,
+                        Metric:   m.name,
+                        Baseline: m.baseline,
+                        Current:  m.current,
+                    })
+                }
+            }
+        }
+    }
+
+    return regressions
+}
+
+// LoadBenchmarks reads benchmarks previously stored by SaveBenchmarks.
+func LoadBenchmarks(path string) ([]Benchmark, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+
+    var benchmarks []Benchmark
+    if err := json.Unmarshal(data, &benchmarks); err != nil {
+        return nil, fmt.Errorf("invalid benchmark file %q: %w", path, err)
+    }
+    return benchmarks, nil
+}
+
+// SaveBenchmarks stores benchmarks into a file, so they can serve as a baseline for future comparisons.
+func SaveBenchmarks(path string, benchmarks []Benchmark) error {
+    data, err := json.MarshalIndent(benchmarks, "", "    ")
+    if err != nil {
+        return err
+    }
+    return os.WriteFile(path, append(data, '\n'), 0644)
+}
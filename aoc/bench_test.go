@@ -0,0 +1,75 @@
+package aoc
+
+import (
+    "path/filepath"
+    "reflect"
+    "testing"
+    "time"
+)
+
+// Benchmark of the day with the same statistics of all phases.
+func benchmark(day int, duration time.Duration, allocs uint64) Benchmark {
+    stats := PhaseStats{Duration: duration, Allocs: allocs, Bytes: allocs * 16}
+    return Benchmark{Day: day, Iterations: 10, Parse: stats, Part1: stats, Part2: stats}
+}
+
+func TestCompareBenchmarks(t *testing.T) {
+    baseline := []Benchmark{benchmark(1, 1000, 100), benchmark(2, 0, 0)}
+    for _, test := range []struct {
+        name     string
+        current  Benchmark
+        expected int
+    }{
+        {"same", benchmark(1, 1000, 100), 0},
+        {"faster", benchmark(1, 500, 50), 0},
+        {"just under threshold", benchmark(1, 1199, 119), 0},
+        {"on threshold", benchmark(1, 1200, 120), 0},
+        {"duration just over threshold", benchmark(1, 1201, 100), 3},
+        {"allocations just over threshold", benchmark(1, 1000, 121), 3},
+        {"both over threshold", benchmark(1, 2000, 200), 6},
+        {"missing in baseline", benchmark(3, time.Second, 1000), 0},
+        {"zero baseline", benchmark(2, time.Second, 1000), 0},
+    } {
+        regressions := CompareBenchmarks([]Benchmark{test.current}, baseline, 0.2)
+        if len(regressions) != test.expected {
+            t.Errorf("%s: got regressions %v, want %d", test.name, regressions, test.expected)
+        }
+        for _, r := range regressions {
+            if r.Day != test.current.Day || r.Current <= r.Baseline {
+                t.Errorf("%s: got regression %v", test.name, r)
+            }
+        }
+    }
+
+    regressions := CompareBenchmarks([]Benchmark{benchmark(1, 1500, 100)}, baseline, 0.2)
+    expected := []Regression{
+        {Day: 1, Phase: PhaseParse, Metric: "duration", Baseline: 1000, Current: 1500},
+        {Day: 1, Phase: PhasePart1, Metric: "duration", Baseline: 1000, Current: 1500},
+        {Day: 1, Phase: PhasePart2, Metric: "duration", Baseline: 1000, Current: 1500},
+    }
+    if !reflect.DeepEqual(regressions, expected) {
+        t.Errorf("got regressions %v, want %v", regressions, expected)
+    }
+    if text := regressions[0].String(); text != "day 1, parse: duration regressed from 1µs to 1.5µs (+50.0%)" {
+        t.Errorf("got description %q", text)
+    }
+}
+
+func TestSaveAndLoadBenchmarks(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "baseline.json")
+    benchmarks := []Benchmark{benchmark(1, 1500*time.Microsecond, 42), benchmark(8, 0, 0)}
+    if err := SaveBenchmarks(path, benchmarks); err != nil {
+        t.Fatal(err)
+    }
+    loaded, err := LoadBenchmarks(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !reflect.DeepEqual(loaded, benchmarks) {
+        t.Errorf("got %v, want %v", loaded, benchmarks)
+    }
+
+    if _, err := LoadBenchmarks(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+        t.Error("expected error of missing file")
+    }
+}
@@ -0,0 +1,3 @@
+// Package aoc keeps the registry of all implemented Advent of Code 2020 puzzles and the helpers the runner uses to
+// execute them.
+package aoc
@@ -0,0 +1,41 @@
+package aoc
+
+import (
+    "errors"
+    "fmt"
+    "io"
+    "io/fs"
+    "os"
+    "path/filepath"
+)
+
+// StdinPath is the input path which makes the runner read the puzzle input from standard input.
+const StdinPath = "-"
+
+// DefaultInputPath returns the location of input file for given day. Inputs are stored in "DayN" directory (next to
+// the day's solution) under given base directory.
+func DefaultInputPath(baseDir string, day int) string {
+    return filepath.Join(baseDir, fmt.Sprintf("Day%d", day), "input")
+}
+
+// ReadInput loads the whole input of given day into memory, so it can be handed over to both parts of the puzzle.
+// Path "-" reads the input from standard input. Errors always name the day and path they relate to.
+func ReadInput(day int, path string) ([]byte, error) {
+    if path == StdinPath {
+        data, err := io.ReadAll(os.Stdin)
+        if err != nil {
+            return nil, fmt.Errorf("day %d: could not read input from standard input: %w", day, err)
+        }
+        return data, nil
+    }
+
+    data, err := os.ReadFile(path)
+    switch {
+    case errors.Is(err, fs.ErrNotExist):
+        return nil, fmt.Errorf("day %d: input file %q does not exist", day, path)
+    case err != nil:
+        return nil, fmt.Errorf("day %d: could not read input file %q: %w", day, path, err)
+    }
+
+    return data, nil
+}
@@ -0,0 +1,91 @@
+package aoc
+
+import (
+    "fmt"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+// Factory creates a new, empty solver of a single day's puzzle.
+type Factory func() Solver
+
+var solvers = make(map[int]Factory)
+
+// Register makes the solver of given day available to the runner. Days register themselves from their init
+// functions, therefore registering the same day twice is considered a programming error and panics.
+func Register(day int, factory Factory) {
+    if day <= 0 {
+        panic(fmt.Sprintf("aoc: invalid day number %d", day))
+    }
+    if _, ok := solvers[day]; ok {
+        panic(fmt.Sprintf("aoc: solver for day %d registered twice", day))
+    }
+    solvers[day] = factory
+}
+
+// Lookup returns the solver factory of given day together with flag whether such day is registered.
+func Lookup(day int) (Factory, bool) {
+    factory, ok := solvers[day]
+    return factory, ok
+}
+
+// Days returns the numbers of all registered days in ascending order.
+func Days() []int {
+    var days []int
+    for day := range solvers {
+        days = append(days, day)
+    }
+    sort.Ints(days)
+    return days
+}
+
+// ParseDays translates day selection given on command line into the list of day numbers. Selection can be "all",
+// single day ("7"), range of days ("1-12") or comma separated combination of these ("1,3,5-7").
+// Every selected day has to be registered.
+func ParseDays(spec string) ([]int, error) {
+    if spec == "all" {
+        return Days(), nil
+    }
+
+    var days []int
+    seen := make(map[int]bool)
+    for _, part := range strings.Split(spec, ",") {
+        first, last, err := parseDayRange(part)
+        if err != nil {
+            return nil, err
+        }
+
+        for day := first; day <= last; day++ {
+            if _, ok := solvers[day]; !ok {
+                return nil, fmt.Errorf("day %d has no registered solver", day)
+            }
+            if !seen[day] {
+                seen[day] = true
+                days = append(days, day)
+            }
+        }
+    }
+
+    return days, nil
+}
+
+// Parses either a single day number ("7") or an inclusive range of days ("1-12").
+func parseDayRange(value string) (int, int, error) {
+    bounds := strings.SplitN(strings.TrimSpace(value), "-", 2)
+
+    first, err := strconv.Atoi(bounds[0])
+    if err != nil {
+        return 0, 0, fmt.Errorf("invalid day %q", value)
+    }
+    if len(bounds) == 1 {
+        return first, first, nil
+    }
+
+    last, err := strconv.Atoi(bounds[1])
+    if err != nil || last < first {
+        return 0, 0, fmt.Errorf("invalid day range %q", value)
+    }
+
+    return first, last, nil
+}
@@ -0,0 +1,148 @@
+package aoc
+
+import (
+    "encoding/csv"
+    "encoding/json"
+    "fmt"
+    "io"
+    "strconv"
+)
+
+// Supported output formats of results.
+const (
+    FormatText = "text"
+    FormatJSON = "json"
+    FormatCSV  = "csv"
+)
+
+// ResultWriter outputs results of solved puzzle parts in one of the supported formats. Close has to be called once
+// all results are written, as some formats are written as a whole.
+type ResultWriter interface {
+    Write(result Result) error
+    Close() error
+}
+
+// NewResultWriter creates writer of results in given format ("text", "json" or "csv").
+func NewResultWriter(w io.Writer, format string) (ResultWriter, error) {
+    switch format {
+    case FormatText:
+        return &textWriter{w: w}, nil
+    case FormatJSON:
+        return &jsonWriter{w: w}, nil
+    case FormatCSV:
+        return newCSVWriter(w)
+    default:
+        return nil, fmt.Errorf("unknown output format %q (use text, json or csv)", format)
+    }
+}
+
+// Human readable output. Results are grouped under the heading of their day.
+type textWriter struct {
+    w       io.Writer
+    lastDay int
+}
+
+func (tw *textWriter) Write(result Result) error {
+    if result.Day != tw.lastDay {
+        tw.lastDay = result.Day
+        if _, err := fmt.Fprintf(tw.w, "--- Day %d ---\n", result.Day); err != nil {
+            return err
+        }
+    }
+
+    if result.Err != nil {
+        _, err := fmt.Fprintf(tw.w, "PART %d: failed: %v\n", result.Part, result.Err)
+        return err
+    }
+    _, err := fmt.Fprintf(tw.w, "PART %d: %v (%v)\n", result.Part, result.Answer, result.Duration)
+    return err
+}
+
+func (tw *textWriter) Close() error {
+    return nil
+}
+
+// Record shared by machine readable formats.
+type resultRecord struct {
+    Day        int     `json:"day"`
+    Part       int     `json:"part"`
+    Answer     *Answer `json:"answer"`
+    DurationNs int64   `json:"duration_ns"`
+    Status     string  `json:"status"`
+    Error      string  `json:"error,omitempty"`
+}
+
+func newResultRecord(result Result) resultRecord {
+    record := resultRecord{
+        Day:        result.Day,
+        Part:       result.Part,
+        DurationNs: result.Duration.Nanoseconds(),
+        Status:     "ok",
+    }
+    if result.Err != nil {
+        record.Status = "error"
+        record.Error = result.Err.Error()
+    } else {
+        answer := result.Answer
+        record.Answer = &answer
+    }
+    return record
+}
+
+// JSON output is a single array of all results, therefore it's written on close.
+type jsonWriter struct {
+    w       io.Writer
+    records []resultRecord
+}
+
+func (jw *jsonWriter) Write(result Result) error {
+    jw.records = append(jw.records, newResultRecord(result))
+    return nil
+}
+
+func (jw *jsonWriter) Close() error {
+    records := jw.records
+    if records == nil {
+        records = []resultRecord{}
+    }
+
+    encoder := json.NewEncoder(jw.w)
+    encoder.SetIndent("", "    ")
+    return encoder.Encode(records)
+}
+
+// CSV output with header row, one row per result.
+type csvWriter struct {
+    w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+    cw := &csvWriter{w: csv.NewWriter(w)}
+    if err := cw.w.Write([]string{"day", "part", "answer", "duration_ns", "status", "error"}); err != nil {
+        return nil, err
+    }
+    return cw, nil
+}
+
+func (cw *csvWriter) Write(result Result) error {
+    record := newResultRecord(result)
+
+    answer := ""
+    if record.Answer != nil {
+        answer = record.Answer.String()
+    }
+
+    return cw.w.Write([]string{
+        strconv.Itoa(record.Day),
+        strconv.Itoa(record.Part),
+        answer,
+        strconv.FormatInt(record.DurationNs, 10),
+        record.Status,
+        record.Error,
+    })
+}
+
+func (cw *csvWriter) Close() error {
+    cw.w.Flush()
+    return cw.w.Error()
+}
@@ -0,0 +1,100 @@
+package aoc
+
+import (
+    "bytes"
+    "errors"
+    "testing"
+    "time"
+)
+
+// Writes the results in the format and returns the whole output.
+func writeResults(t *testing.T, format string, results []Result) string {
+    t.Helper()
+    var output bytes.Buffer
+    writer, err := NewResultWriter(&output, format)
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, result := range results {
+        if err := writer.Write(result); err != nil {
+            t.Fatal(err)
+        }
+    }
+    if err := writer.Close(); err != nil {
+        t.Fatal(err)
+    }
+    return output.String()
+}
+
+// Day 1 solved, day 2 failed with error message that has to be quoted in CSV.
+var exampleResults = append([]Result{
+    {Day: 1, Part: 1, Answer: 514579, Duration: 1500 * time.Microsecond},
+    {Day: 1, Part: 2, Answer: 241861950, Duration: 2 * time.Millisecond},
+}, FailedResults(2, errors.New(`could not read input "Day2/input", missing file`))...)
+
+func TestResultWriters(t *testing.T) {
+    for _, test := range []struct {
+        format   string
+        results  []Result
+        expected string
+    }{
+        {FormatText, exampleResults, `--- Day 1 ---
+PART 1: 514579 (1.5ms)
+PART 2: 241861950 (2ms)
+--- Day 2 ---
+PART 1: failed: could not read input "Day2/input", missing file
+PART 2: failed: could not read input "Day2/input", missing file
+`},
+        {FormatText, nil, ""},
+        {FormatJSON, exampleResults, `[
+    {
+        "day": 1,
+        "part": 1,
+        "answer": 514579,
+        "duration_ns": 1500000,
+        "status": "ok"
+    },
+    {
+        "day": 1,
+        "part": 2,
+        "answer": 241861950,
+        "duration_ns": 2000000,
+        "status": "ok"
+    },
+    {
+        "day": 2,
+        "part": 1,
+        "answer": null,
+        "duration_ns": 0,
+        "status": "error",
+        "error": "could not read input \"Day2/input\", missing file"
+    },
+    {
+        "day": 2,
+        "part": 2,
+        "answer": null,
+        "duration_ns": 0,
+        "status": "error",
+        "error": "could not read input \"Day2/input\", missing file"
+    }
+]
+`},
+        // Empty run is an empty array rather than null.
+        {FormatJSON, nil, "[]\n"},
+        {FormatCSV, exampleResults, `day,part,answer,duration_ns,status,error
+1,1,514579,1500000,ok,
+1,2,241861950,2000000,ok,
+2,1,,0,error,"could not read input ""Day2/input"", missing file"
+2,2,,0,error,"could not read input ""Day2/input"", missing file"
+`},
+        {FormatCSV, nil, "day,part,answer,duration_ns,status,error\n"},
+    } {
+        if output := writeResults(t, test.format, test.results); output != test.expected {
+            t.Errorf("%s of %d results: got\n%s\nwant\n%s", test.format, len(test.results), output, test.expected)
+        }
+    }
+
+    if _, err := NewResultWriter(&bytes.Buffer{}, "xml"); err == nil {
+        t.Error("expected error of unknown format")
+    }
+}
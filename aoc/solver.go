@@ -0,0 +1,68 @@
+package aoc
+
+import (
+    "bytes"
+    "errors"
+    "fmt"
+    "io"
+    "time"
+
+    "AdventOfCode20/input"
+)
+
+// ErrNoAnswer is reported by solvers whose input does not lead to any answer of the puzzle part.
+var ErrNoAnswer = errors.New("desired result could not be found")
+
+// Answer is the result of a single puzzle part.
+type Answer int
+
+func (a Answer) String() string {
+    return fmt.Sprintf("%d", int(a))
+}
+
+// Solver solves a single day's puzzle. Input is parsed only once and both parts then work with the parsed data,
+// which they must not modify (parts can be solved in any order and repeatedly). Invalid parts of the input are passed
+// to the error handler, which decides whether they are skipped or whether the parsing fails.
+type Solver interface {
+    Parse(reader io.Reader, onError input.ErrorHandler) error
+    Part1() (Answer, error)
+    Part2() (Answer, error)
+}
+
+// Result holds the outcome of a single puzzle part.
+type Result struct {
+    Day      int
+    Part     int
+    Answer   Answer
+    Duration time.Duration
+    Err      error
+}
+
+// FailedResults returns results of both parts of given day failed with the same error. It is used when puzzle parts
+// cannot be solved at all (e.g. due to missing or invalid input).
+func FailedResults(day int, err error) []Result {
+    return []Result{{Day: day, Part: 1, Err: err}, {Day: day, Part: 2, Err: err}}
+}
+
+// Solve parses the input with a new solver of given day and solves both its parts. Parsing error prevents solving of
+// either part and is returned directly, errors of individual parts are reported within their results.
+func Solve(day int, data []byte, onError input.ErrorHandler) ([]Result, error) {
+    factory, ok := Lookup(day)
+    if !ok {
+        return nil, fmt.Errorf("day %d has no registered solver", day)
+    }
+
+    solver := factory()
+    if err := solver.Parse(bytes.NewReader(data), onError); err != nil {
+        return nil, fmt.Errorf("day %d: could not parse input: %w", day, err)
+    }
+
+    results := make([]Result, 0, 2)
+    for part, solve := range []func() (Answer, error){solver.Part1, solver.Part2} {
+        start := time.Now()
+        answer, err := solve()
+        results = append(results, Result{Day: day, Part: part + 1, Answer: answer, Duration: time.Since(start), Err: err})
+    }
+
+    return results, nil
+}
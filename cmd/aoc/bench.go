@@ -0,0 +1,89 @@
+package main
+
+import (
+    "fmt"
+    "os"
+    "text/tabwriter"
+    "time"
+
+    "AdventOfCode20/aoc"
+)
+
+func benchCommand(args []string) int {
+    flags := tool.NewFlagSet("bench")
+    inputs := registerInputFlags(flags)
+    iterations := flags.Int("n", 10, "number of iterations averaged for every day")
+    baselinePath := flags.String("baseline", "", "file with baseline benchmarks to compare against")
+    savePath := flags.String("save", "", "file to store the benchmarks into (usable as future baseline)")
+    threshold := flags.Float64("threshold", 0.2, "allowed relative increase against baseline (0.2 means 20%)")
+
+    days, ok := parseDaysArgument(flags, args)
+    if !ok || !inputs.validate(days) {
+        return 2
+    }
+
+    var baseline []aoc.Benchmark
+    if *baselinePath != "" {
+        var err error
+        if baseline, err = aoc.LoadBenchmarks(*baselinePath); err != nil {
+            fmt.Fprintf(os.Stderr, "aoc: could not load baseline: %v\n", err)
+            return 2
+        }
+    }
+
+    var benchmarks []aoc.Benchmark
+    failed := false
+    for _, day := range days {
+        data, err := aoc.ReadInput(day, inputs.path(day))
+        if err == nil {
+            var benchmark aoc.Benchmark
+            if benchmark, err = aoc.Bench(day, data, *iterations); err == nil {
+                benchmarks = append(benchmarks, benchmark)
+            }
+        }
+        if err != nil {
+            fmt.Fprintf(os.Stderr, "aoc: %v\n", err)
+            failed = true
+        }
+    }
+
+    printBenchmarks(benchmarks)
+
+    if *savePath != "" {
+        if err := aoc.SaveBenchmarks(*savePath, benchmarks); err != nil {
+            fmt.Fprintf(os.Stderr, "aoc: could not save benchmarks: %v\n", err)
+            failed = true
+        }
+    }
+
+    if baseline != nil {
+        regressions := aoc.CompareBenchmarks(benchmarks, baseline, *threshold)
+        for _, regression := range regressions {
+            fmt.Printf("REGRESSION: %v\n", regression)
+        }
+        if len(regressions) > 0 {
+            failed = true
+        }
+    }
+
+    if failed {
+        return 1
+    }
+    return 0
+}
+
+func printBenchmarks(benchmarks []aoc.Benchmark) {
+    w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+    fmt.Fprintln(w, "DAY\tPARSE\tALLOCS\tPART 1\tALLOCS\tPART 2\tALLOCS\tTOTAL\t")
+
+    var total time.Duration
+    for _, b := range benchmarks {
+        dayTotal := b.Parse.Duration + b.Part1.Duration + b.Part2.Duration
+        total += dayTotal
+        fmt.Fprintf(w, "%d\t%v\t%d\t%v\t%d\t%v\t%d\t%v\t\n", b.Day,
+            b.Parse.Duration, b.Parse.Allocs, b.Part1.Duration, b.Part1.Allocs, b.Part2.Duration, b.Part2.Allocs, dayTotal)
+    }
+    fmt.Fprintf(w, "\t\t\t\t\t\t\t%v\t\n", total)
+
+    w.Flush()
+}
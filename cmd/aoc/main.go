@@ -0,0 +1,165 @@
+package main
+
+import (
+    "flag"
+    "fmt"
+    "os"
+
+    "AdventOfCode20/aoc"
+    _ "AdventOfCode20/days"
+    "AdventOfCode20/input"
+    "AdventOfCode20/internal/cli"
+)
+
+const usage = `Usage:
+    aoc run [flags] <days>      solve puzzles of selected days
+    aoc bench [flags] <days>    measure parsing and solving of selected days
+
+Days can be given as "all", a single day ("7"), a range ("1-12") or their
+comma separated combination ("1,3,5-7"). Run "aoc <command> -h" to list
+flags of the command.
+`
+
+// Tool describes the command in usage messages of its subcommands.
+var tool = cli.Tool{Name: "aoc", Operands: "<days>"}
+
+func main() {
+    if len(os.Args) < 2 {
+        fmt.Fprint(os.Stderr, usage)
+        os.Exit(2)
+    }
+
+    switch os.Args[1] {
+    case "run":
+        os.Exit(runCommand(os.Args[2:]))
+    case "bench":
+        os.Exit(benchCommand(os.Args[2:]))
+    default:
+        fmt.Fprint(os.Stderr, usage)
+        os.Exit(2)
+    }
+}
+
+func runCommand(args []string) int {
+    flags := tool.NewFlagSet("run")
+    inputs := registerInputFlags(flags)
+    format := flags.String("format", aoc.FormatText, "output format: text, json or csv")
+    strict := flags.Bool("strict", false, "fail on invalid input lines instead of skipping them")
+
+    days, ok := parseDaysArgument(flags, args)
+    if !ok || !inputs.validate(days) {
+        return 2
+    }
+
+    writer, err := aoc.NewResultWriter(os.Stdout, *format)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "aoc: %v\n", err)
+        return 2
+    }
+
+    mode := input.Lenient
+    if *strict {
+        mode = input.Strict
+    }
+
+    failed := false
+    var skipped []input.Diagnostic
+    for _, day := range days {
+        path := inputs.path(day)
+        diagnostics := input.NewDiagnostics(inputName(path), mode)
+
+        for _, result := range runDay(day, path, diagnostics) {
+            if result.Err != nil {
+                failed = true
+            }
+            if err := writer.Write(result); err != nil {
+                fmt.Fprintf(os.Stderr, "aoc: could not write result: %v\n", err)
+                return 1
+            }
+        }
+        skipped = append(skipped, diagnostics.Diagnostics()...)
+    }
+    if err := writer.Close(); err != nil {
+        fmt.Fprintf(os.Stderr, "aoc: could not write results: %v\n", err)
+        return 1
+    }
+
+    // Strict mode reports the invalid line as the failure of the day, only lenient mode skips any.
+    if mode == input.Lenient {
+        input.WriteSummary(os.Stderr, skipped)
+    }
+
+    if failed {
+        return 1
+    }
+    return 0
+}
+
+// Loads the input of given day and solves both parts of its puzzle. Input is read only once, as it might come from
+// standard input. Problems with the input are reported as failures of both parts, invalid lines are passed to the
+// diagnostics.
+func runDay(day int, path string, diagnostics *input.Diagnostics) []aoc.Result {
+    data, err := aoc.ReadInput(day, path)
+    if err != nil {
+        return aoc.FailedResults(day, err)
+    }
+
+    results, err := aoc.Solve(day, data, diagnostics.Handle)
+    if err != nil {
+        return aoc.FailedResults(day, err)
+    }
+    return results
+}
+
+// Input related flags shared by all commands.
+type inputFlags struct {
+    input   *string
+    baseDir *string
+}
+
+func registerInputFlags(flags *flag.FlagSet) inputFlags {
+    return inputFlags{
+        input:   flags.String("input", "", "input file of the selected day, \"-\" reads standard input (single day only)"),
+        baseDir: flags.String("dir", ".", "directory containing the DayN/input files"),
+    }
+}
+
+func (f inputFlags) validate(days []int) bool {
+    if *f.input != "" && len(days) != 1 {
+        fmt.Fprintln(os.Stderr, "aoc: -input can only be used together with a single day")
+        return false
+    }
+    return true
+}
+
+// Name of the input used in diagnostics.
+func inputName(path string) string {
+    if path == aoc.StdinPath {
+        return "<stdin>"
+    }
+    return path
+}
+
+// Returns explicitly given input path or the default one of given day.
+func (f inputFlags) path(day int) string {
+    if *f.input != "" {
+        return *f.input
+    }
+    return aoc.DefaultInputPath(*f.baseDir, day)
+}
+
+// Parses command arguments (flags and exactly one day selection) and returns the selected days. Problems are reported
+// to the user, the flag only signals whether the command can continue.
+func parseDaysArgument(flags *flag.FlagSet, args []string) ([]int, bool) {
+    operand, ok := cli.SingleOperand(flags, args)
+    if !ok {
+        return nil, false
+    }
+
+    days, err := aoc.ParseDays(operand)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "aoc: %v\n", err)
+        return nil, false
+    }
+    return days, true
+}
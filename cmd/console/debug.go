@@ -0,0 +1,374 @@
+package main
+
+import (
+    "bufio"
+    "fmt"
+    "io"
+    "os"
+    "sort"
+    "strconv"
+    "strings"
+
+    "AdventOfCode20/internal/cli"
+    "AdventOfCode20/vm"
+)
+
+const debugHelp = `Commands:
+    s, step [n]             execute next n instructions (default 1)
+    b, back [n]             revert last n executed instructions (default 1)
+    c, continue             run until breakpoint, watch or the end of the program
+    break <index|opcode>    stop before the instruction on index or with opcode
+    delete <index|opcode>   remove the breakpoint
+    breaks                  list breakpoints
+    watch [value]           stop when the accumulator changes (or reaches the value)
+    unwatch                 remove the watch
+    r, regs                 show registers and state of the machine
+    l, list [n]             show n instructions around the pointer with their execution counts
+    counts                  show execution counts of all executed instructions
+    trace [n]               show last n executed instructions (default 10)
+    reset                   start the program again (breakpoints and watch are kept)
+    h, help                 show this help
+    q, quit                 leave the debugger
+Empty line repeats the last command.
+`
+
+// Default step limit of the debugger. Continuing a looping program without loop detection has to stop somewhere, the
+// trace reverse steps are made of grows with every step.
+const defaultDebugSteps = 1000000
+
+// Debugs the program with commands read from stdin, the program itself has to be a file.
+func debugCommand(args []string, stdin io.Reader, stdout io.Writer) int {
+    flags := tool.NewFlagSet("debug")
+    loop := flags.String("loop", "revisit", "loop detection: revisit, state or none")
+    maxSteps := flags.Int("max-steps", defaultDebugSteps, "maximal number of executed steps, 0 means no limit")
+
+    program, path, ok := parseProgramArgument(flags, args)
+    if !ok {
+        return 2
+    }
+    if path == cli.Stdio {
+        fmt.Fprintln(os.Stderr, "console: debugger reads commands from standard input, the program has to be a file")
+        return 2
+    }
+    detection, err := parseLoopDetection(*loop)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "console: %v\n", err)
+        return 2
+    }
+
+    if *maxSteps < 0 {
+        fmt.Fprintln(os.Stderr, "console: maximal number of steps cannot be negative")
+        return 2
+    }
+
+    // Trace is the history reverse steps are made of.
+    config := vm.Config{LoopDetection: detection, Trace: true, MaxSteps: *maxSteps}
+    d := newDebugger(vm.New(program, config), stdout)
+    if err := d.repl(stdin); err != nil {
+        fmt.Fprintf(os.Stderr, "console: %v\n", err)
+        return 1
+    }
+    return 0
+}
+
+func parseLoopDetection(name string) (vm.LoopDetection, error) {
+    switch name {
+    case "revisit":
+        return vm.DetectRevisit, nil
+    case "state":
+        return vm.DetectRepeatedState, nil
+    case "none":
+        return vm.DetectNone, nil
+    default:
+        return 0, fmt.Errorf("unknown loop detection %q", name)
+    }
+}
+
+// Interactive debugger of the handheld console program.
+type debugger struct {
+    machine      *vm.Machine
+    out          io.Writer
+    breakIndexes map[int]bool
+    breakOpcodes map[string]bool
+    watching     bool
+    // Value the watched accumulator has to reach, nil stops on any change.
+    watchValue *int
+}
+
+func newDebugger(machine *vm.Machine, out io.Writer) *debugger {
+    return &debugger{
+        machine:      machine,
+        out:          out,
+        breakIndexes: make(map[int]bool),
+        breakOpcodes: make(map[string]bool),
+    }
+}
+
+// Reads commands line by line until the end of the input or the quit command.
+func (d *debugger) repl(reader io.Reader) error {
+    scanner := bufio.NewScanner(reader)
+    last := ""
+    d.printLocation()
+    for {
+        fmt.Fprint(d.out, "(console) ")
+        if !scanner.Scan() {
+            fmt.Fprintln(d.out)
+            return scanner.Err()
+        }
+
+        line := strings.TrimSpace(scanner.Text())
+        if line == "" {
+            line = last
+        }
+        if line == "" {
+            continue
+        }
+        last = line
+
+        fields := strings.Fields(line)
+        quit, err := d.execute(fields[0], fields[1:])
+        if err != nil {
+            fmt.Fprintf(d.out, "error: %v\n", err)
+        }
+        if quit {
+            return nil
+        }
+    }
+}
+
+// Executes single command, the flag tells whether the debugger should quit.
+func (d *debugger) execute(command string, args []string) (bool, error) {
+    switch command {
+    case "s", "step":
+        n, err := optionalCount(args, 1)
+        if err != nil {
+            return false, err
+        }
+        for i := 0; i < n && d.machine.ExitCode() == vm.Running; i++ {
+            d.step()
+        }
+        d.printLocation()
+    case "b", "back":
+        n, err := optionalCount(args, 1)
+        if err != nil {
+            return false, err
+        }
+        for i := 0; i < n; i++ {
+            if !d.machine.StepBack() {
+                fmt.Fprintln(d.out, "at the start of the program")
+                break
+            }
+        }
+        d.printLocation()
+    case "c", "continue":
+        d.cont()
+        d.printLocation()
+    case "break", "delete":
+        if len(args) != 1 {
+            return false, fmt.Errorf("%s expects instruction index or opcode", command)
+        }
+        return false, d.setBreakpoint(args[0], command == "break")
+    case "breaks":
+        d.printBreakpoints()
+    case "watch":
+        return false, d.setWatch(args)
+    case "unwatch":
+        d.watching, d.watchValue = false, nil
+    case "r", "regs":
+        d.printRegisters()
+    case "l", "list":
+        n, err := optionalCount(args, 5)
+        if err != nil {
+            return false, err
+        }
+        d.printListing(n)
+    case "counts":
+        d.printCounts()
+    case "trace":
+        n, err := optionalCount(args, 10)
+        if err != nil {
+            return false, err
+        }
+        trace := d.machine.Trace()
+        if len(trace) > n {
+            trace = trace[len(trace)-n:]
+        }
+        for _, entry := range trace {
+            fmt.Fprintln(d.out, entry)
+        }
+    case "reset":
+        d.machine.Reset()
+        d.printLocation()
+    case "h", "help":
+        fmt.Fprint(d.out, debugHelp)
+    case "q", "quit":
+        return true, nil
+    default:
+        return false, fmt.Errorf("unknown command %q, type \"help\" to list commands", command)
+    }
+    return false, nil
+}
+
+// Executes single instruction and reports the change of the watched accumulator. The flag tells whether the watch
+// was triggered.
+func (d *debugger) step() bool {
+    before := d.machine.Accumulator()
+    d.machine.Step()
+    after := d.machine.Accumulator()
+    if !d.watching || before == after {
+        return false
+    }
+
+    fmt.Fprintf(d.out, "accumulator: %d -> %d\n", before, after)
+    return d.watchValue == nil || *d.watchValue == after
+}
+
+// Runs the program until it stops or reaches a breakpoint or triggers the watch. The instruction the machine stands
+// on is always executed, so continuing from a breakpoint does not stop on it again. Reaching the step limit is
+// reported, as the program may still go on.
+func (d *debugger) cont() {
+    for d.machine.ExitCode() == vm.Running {
+        if d.step() {
+            return
+        }
+        if d.machine.ExitCode() == vm.StepLimitExceeded {
+            fmt.Fprintf(d.out, "stopped after %d step(s), the limit of -max-steps\n", d.machine.Steps())
+            return
+        }
+        if d.machine.ExitCode() != vm.Running {
+            return
+        }
+
+        pointer := d.machine.Registers().Pointer
+        if d.breakIndexes[pointer] || d.breakOpcodes[d.machine.Instruction(pointer).Op.Name] {
+            fmt.Fprintf(d.out, "breakpoint at %d\n", pointer)
+            return
+        }
+    }
+}
+
+func (d *debugger) setBreakpoint(target string, enable bool) error {
+    if index, err := strconv.Atoi(target); err == nil {
+        if index < 0 || index >= d.machine.Program().Len() {
+            return fmt.Errorf("instruction %d is out of the program", index)
+        }
+        if enable {
+            d.breakIndexes[index] = true
+        } else {
+            delete(d.breakIndexes, index)
+        }
+        return nil
+    }
+
+    if _, ok := d.machine.Program().Set.Lookup(target); !ok {
+        return fmt.Errorf("unknown opcode %q", target)
+    }
+    if enable {
+        d.breakOpcodes[target] = true
+    } else {
+        delete(d.breakOpcodes, target)
+    }
+    return nil
+}
+
+func (d *debugger) setWatch(args []string) error {
+    switch len(args) {
+    case 0:
+        d.watching, d.watchValue = true, nil
+    case 1:
+        value, err := strconv.Atoi(args[0])
+        if err != nil {
+            return fmt.Errorf("invalid watched value %q", args[0])
+        }
+        d.watching, d.watchValue = true, &value
+    default:
+        return fmt.Errorf("watch expects at most one value")
+    }
+    return nil
+}
+
+func (d *debugger) printBreakpoints() {
+    var indexes []int
+    for index := range d.breakIndexes {
+        indexes = append(indexes, index)
+    }
+    sort.Ints(indexes)
+    var opcodes []string
+    for opcode := range d.breakOpcodes {
+        opcodes = append(opcodes, opcode)
+    }
+    sort.Strings(opcodes)
+
+    if len(indexes) == 0 && len(opcodes) == 0 {
+        fmt.Fprintln(d.out, "no breakpoints")
+    }
+    for _, index := range indexes {
+        fmt.Fprintf(d.out, "    %4d: %v\n", index, d.machine.Instruction(index))
+    }
+    for _, opcode := range opcodes {
+        fmt.Fprintf(d.out, "    every %s\n", opcode)
+    }
+}
+
+func (d *debugger) printRegisters() {
+    registers := d.machine.Registers()
+    fmt.Fprintf(d.out, "accumulator %d, pointer %d, %d step(s), %v\n", registers.Accumulator, registers.Pointer,
+        d.machine.Steps(), d.machine.ExitCode())
+}
+
+// Prints the registers and the instruction the machine stands on.
+func (d *debugger) printLocation() {
+    d.printRegisters()
+    pointer := d.machine.Registers().Pointer
+    if pointer >= 0 && pointer < d.machine.Program().Len() {
+        d.printInstruction(pointer)
+    }
+}
+
+// Prints n instructions before and after the pointer.
+func (d *debugger) printListing(n int) {
+    pointer := d.machine.Registers().Pointer
+    for index := pointer - n; index <= pointer+n; index++ {
+        if index >= 0 && index < d.machine.Program().Len() {
+            d.printInstruction(index)
+        }
+    }
+}
+
+func (d *debugger) printCounts() {
+    for index := 0; index < d.machine.Program().Len(); index++ {
+        if d.machine.ExecCount(index) > 0 {
+            d.printInstruction(index)
+        }
+    }
+}
+
+// Prints the instruction with its execution count, marking the pointer ("=>") and breakpoints ("*").
+func (d *debugger) printInstruction(index int) {
+    inst := d.machine.Instruction(index)
+    marker := "  "
+    if index == d.machine.Registers().Pointer {
+        marker = "=>"
+    }
+    breakpoint := " "
+    if d.breakIndexes[index] || d.breakOpcodes[inst.Op.Name] {
+        breakpoint = "*"
+    }
+    fmt.Fprintf(d.out, "%s%s %4d: %-10v executed %dx\n", marker, breakpoint, index, inst, d.machine.ExecCount(index))
+}
+
+// Parses optional positive count argument of the command.
+func optionalCount(args []string, defaultCount int) (int, error) {
+    switch len(args) {
+    case 0:
+        return defaultCount, nil
+    case 1:
+        n, err := strconv.Atoi(args[0])
+        if err != nil || n <= 0 {
+            return 0, fmt.Errorf("invalid count %q", args[0])
+        }
+        return n, nil
+    default:
+        return 0, fmt.Errorf("expected at most one count")
+    }
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+    "fmt"
+    "os"
+
+    "AdventOfCode20/vm"
+)
+
+func disasmCommand(args []string) int {
+    flags := tool.NewFlagSet("disasm")
+    summary := flags.Bool("summary", true, "write problems found by the analysis after the listing")
+
+    program, _, ok := parseProgramArgument(flags, args)
+    if !ok {
+        return 2
+    }
+
+    analysis := vm.Analyze(program)
+    if err := analysis.WriteListing(os.Stdout); err != nil {
+        fmt.Fprintf(os.Stderr, "console: could not write listing: %v\n", err)
+        return 1
+    }
+    if *summary {
+        fmt.Println()
+        if err := analysis.WriteSummary(os.Stdout); err != nil {
+            fmt.Fprintf(os.Stderr, "console: could not write summary: %v\n", err)
+            return 1
+        }
+    }
+    return 0
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+    "fmt"
+    "io"
+    "os"
+
+    "AdventOfCode20/internal/cli"
+    "AdventOfCode20/vm"
+)
+
+func graphCommand(args []string) int {
+    flags := tool.NewFlagSet("graph")
+    format := flags.String("format", "dot", "output format: dot or mermaid")
+    highlight := flags.Bool("highlight", true, "run the program and highlight the executed path and the loop")
+    output := flags.String("o", cli.Stdio, "file to write the graph into, \"-\" writes standard output")
+
+    program, _, ok := parseProgramArgument(flags, args)
+    if !ok {
+        return 2
+    }
+
+    graph := vm.BuildGraph(program)
+    var write func(io.Writer, *vm.Highlight) error
+    switch *format {
+    case "dot":
+        write = graph.WriteDOT
+    case "mermaid":
+        write = graph.WriteMermaid
+    default:
+        fmt.Fprintf(os.Stderr, "console: unknown graph format %q\n", *format)
+        return 2
+    }
+
+    var h *vm.Highlight
+    if *highlight {
+        machine := vm.New(program, vm.Config{Trace: true})
+        machine.Run()
+        // Machine was created with enabled trace, so highlighting cannot fail.
+        h, _ = vm.HighlightRun(machine)
+    }
+
+    if err := cli.WriteOutput(*output, func(w io.Writer) error { return write(w, h) }); err != nil {
+        fmt.Fprintf(os.Stderr, "console: could not write graph: %v\n", err)
+        return 1
+    }
+    return 0
+}
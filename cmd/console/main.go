@@ -0,0 +1,102 @@
+package main
+
+import (
+    "flag"
+    "fmt"
+    "os"
+    "strings"
+
+    "AdventOfCode20/input"
+    "AdventOfCode20/internal/cli"
+    "AdventOfCode20/vm"
+)
+
+const usage = `Usage:
+    console repair [flags] <program>    find the minimal patch making the program terminate
+    console debug [flags] <program>     step through the program interactively
+    console graph [flags] <program>     export control-flow graph to Graphviz DOT or Mermaid
+    console disasm [flags] <program>    print analysed listing of the program with labels
+
+Program is the source file of the handheld console (e.g. Day8/input), "-"
+reads standard input. Run "console <command> -h" to list flags of the
+command.
+`
+
+// Tool describes the command in usage messages of its subcommands.
+var tool = cli.Tool{Name: "console", Operands: "<program>"}
+
+func main() {
+    if len(os.Args) < 2 {
+        fmt.Fprint(os.Stderr, usage)
+        os.Exit(2)
+    }
+
+    switch os.Args[1] {
+    case "repair":
+        os.Exit(repairCommand(os.Args[2:]))
+    case "debug":
+        os.Exit(debugCommand(os.Args[2:], os.Stdin, os.Stdout))
+    case "graph":
+        os.Exit(graphCommand(os.Args[2:]))
+    case "disasm":
+        os.Exit(disasmCommand(os.Args[2:]))
+    default:
+        fmt.Fprint(os.Stderr, usage)
+        os.Exit(2)
+    }
+}
+
+// Parses command arguments (flags and exactly one program file) and loads the program. Problems are reported to the
+// user, the flag only signals whether the command can continue. Path of the program is returned as well.
+func parseProgramArgument(flags *flag.FlagSet, args []string) (*vm.Program, string, bool) {
+    path, ok := cli.SingleOperand(flags, args)
+    if !ok {
+        return nil, "", false
+    }
+
+    program, err := loadProgram(path, vm.Handheld())
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "console: %v\n", err)
+        return nil, "", false
+    }
+    return program, path, true
+}
+
+// Assembles the program of given file. Unlike the puzzle solvers, the tools never skip invalid lines, as any change of
+// instruction indexes would make their output misleading.
+func loadProgram(path string, set *vm.InstructionSet) (*vm.Program, error) {
+    reader, name := os.Stdin, "<stdin>"
+    if path != cli.Stdio {
+        file, err := os.Open(path)
+        if err != nil {
+            return nil, err
+        }
+        defer file.Close()
+        reader, name = file, path
+    }
+
+    diagnostics := input.NewDiagnostics(name, input.Strict)
+    return vm.Assemble(reader, set, diagnostics.Handle)
+}
+
+// Parses comma separated substitutions of opcodes ("jmp=nop,nop=jmp").
+func parseSubstitutions(spec string, set *vm.InstructionSet) ([]vm.Substitution, error) {
+    var substitutions []vm.Substitution
+    for _, part := range strings.Split(spec, ",") {
+        names := strings.Split(strings.TrimSpace(part), "=")
+        if len(names) != 2 {
+            return nil, fmt.Errorf("invalid substitution %q, expected \"from=to\"", part)
+        }
+
+        from, ok := set.Lookup(names[0])
+        if !ok {
+            return nil, fmt.Errorf("unknown opcode %q in substitution %q", names[0], part)
+        }
+        to, ok := set.Lookup(names[1])
+        if !ok {
+            return nil, fmt.Errorf("unknown opcode %q in substitution %q", names[1], part)
+        }
+        substitutions = append(substitutions, vm.Substitution{From: from, To: to})
+    }
+    return substitutions, nil
+}
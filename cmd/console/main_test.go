@@ -0,0 +1,82 @@
+package main
+
+import (
+    "bytes"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+// Example program of the puzzle.
+var exampleProgram = filepath.Join("..", "..", "Day8", "example")
+
+func TestDebugCommand(t *testing.T) {
+    for _, test := range []struct {
+        name     string
+        flags    []string
+        commands string
+        expected string
+    }{
+        {
+            name: "breakpoint, back and watch",
+            // Empty line repeats the listing of breakpoints.
+            commands: "break 4\ncontinue\nback 2\nwatch\ncontinue\nunwatch\nbreaks\n\nbogus\nquit\n",
+            expected: `accumulator 0, pointer 0, 0 step(s), running
+=>     0: nop +0     executed 0x
+(console) (console) breakpoint at 4
+accumulator 5, pointer 4, 6 step(s), running
+=>*    4: jmp -3     executed 0x
+(console) accumulator 2, pointer 7, 4 step(s), running
+=>     7: jmp -4     executed 0x
+(console) (console) accumulator: 2 -> 5
+accumulator 5, pointer 4, 6 step(s), running
+=>*    4: jmp -3     executed 0x
+(console) (console)        4: jmp -3
+(console)        4: jmp -3
+(console) error: unknown command "bogus", type "help" to list commands
+(console) `,
+        },
+        {
+            name:     "loop detection",
+            // Watched value is never reached, the machine stops before entering the loop.
+            commands: "watch 8\ncontinue\nstep 2\ncounts\n",
+            expected: `accumulator 0, pointer 0, 0 step(s), running
+=>     0: nop +0     executed 0x
+(console) (console) accumulator: 0 -> 1
+accumulator: 1 -> 2
+accumulator: 2 -> 5
+accumulator 5, pointer 1, 7 step(s), infinite loop
+=>     1: acc +1     executed 1x
+(console) accumulator 5, pointer 1, 7 step(s), infinite loop
+=>     1: acc +1     executed 1x
+(console)        0: nop +0     executed 1x
+=>     1: acc +1     executed 1x
+       2: jmp +4     executed 1x
+       3: acc +3     executed 1x
+       4: jmp -3     executed 1x
+       6: acc +1     executed 1x
+       7: jmp -4     executed 1x
+(console) ` + "\n",
+        },
+        {
+            name:     "step limit",
+            flags:    []string{"-loop", "none", "-max-steps", "50"},
+            commands: "continue\nback\n",
+            expected: `accumulator 0, pointer 0, 0 step(s), running
+=>     0: nop +0     executed 0x
+(console) stopped after 50 step(s), the limit of -max-steps
+accumulator 41, pointer 2, 50 step(s), step limit exceeded
+=>     2: jmp +4     executed 8x
+(console) accumulator 40, pointer 1, 49 step(s), running
+=>     1: acc +1     executed 8x
+(console) ` + "\n",
+        },
+    } {
+        var output bytes.Buffer
+        args := append(test.flags, exampleProgram)
+        code := debugCommand(args, strings.NewReader(test.commands), &output)
+        if code != 0 || output.String() != test.expected {
+            t.Errorf("%s: got exit %d and output\n%s\nwant\n%s", test.name, code, output.String(), test.expected)
+        }
+    }
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "runtime"
+
+    "AdventOfCode20/internal/cli"
+    "AdventOfCode20/vm"
+)
+
+func repairCommand(args []string) int {
+    flags := tool.NewFlagSet("repair")
+    allow := flags.String("allow", "jmp=nop,nop=jmp", "comma separated opcode substitutions the repair can use")
+    maxEdits := flags.Int("max-edits", 0, "maximal number of changed instructions, 0 means no limit")
+    output := flags.String("o", "", "file to write the patched program into, \"-\" writes standard output")
+    brute := flags.Bool("brute", false, "run every single substitution instead of the search and list all that terminate")
+    workers := flags.Int("workers", runtime.NumCPU(), "number of parallel runs of -brute")
+    maxSteps := flags.Int("max-steps", 0, "maximal number of steps of every -brute run, 0 means no limit")
+    timeout := flags.Duration("timeout", 0, "time limit of all -brute runs together, 0 means no limit")
+
+    program, _, ok := parseProgramArgument(flags, args)
+    if !ok {
+        return 2
+    }
+    substitutions, err := parseSubstitutions(*allow, program.Set)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "console: %v\n", err)
+        return 2
+    }
+    if *brute {
+        ctx := context.Background()
+        if *timeout > 0 {
+            var cancel context.CancelFunc
+            ctx, cancel = context.WithTimeout(ctx, *timeout)
+            defer cancel()
+        }
+        return bruteRepair(ctx, program, substitutions, vm.Config{MaxSteps: *maxSteps}, *workers)
+    }
+
+    repair, err := vm.FindRepair(program, vm.RepairOptions{Substitutions: substitutions, MaxEdits: *maxEdits})
+    if errors.Is(err, vm.ErrNoRepair) {
+        fmt.Fprintf(os.Stderr, "console: %v\n", err)
+        return 1
+    }
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "console: repair failed: %v\n", err)
+        return 1
+    }
+
+    // Patched program on standard output would be mixed with the report, so the report moves to standard error.
+    report := os.Stdout
+    if *output == cli.Stdio {
+        report = os.Stderr
+    }
+    fmt.Fprintf(report, "%d edit(s):\n", len(repair.Edits))
+    for _, edit := range repair.Edits {
+        fmt.Fprintf(report, "    %v\n", edit)
+    }
+    fmt.Fprintf(report, "accumulator: %d\n", repair.Accumulator)
+
+    if *output != "" {
+        err := cli.WriteOutput(*output, func(w io.Writer) error {
+            _, err := io.WriteString(w, repair.Program.String())
+            return err
+        })
+        if err != nil {
+            fmt.Fprintf(os.Stderr, "console: could not write patched program: %v\n", err)
+            return 1
+        }
+    }
+    return 0
+}
+
+// Tries every single substitution by running the patched program, the runs share the original program in parallel.
+// It is the slow but simple cross-check of the repair search.
+func bruteRepair(ctx context.Context, program *vm.Program, substitutions []vm.Substitution, config vm.Config,
+    workers int) int {
+    patches := vm.SingleSubstitutions(program, substitutions)
+    found := 0
+    unfinished := 0
+    for _, outcome := range vm.RunPatches(ctx, program, patches, config, workers) {
+        if outcome.ExitCode == vm.StepLimitExceeded || outcome.ExitCode == vm.Cancelled {
+            unfinished++
+        }
+        if outcome.ExitCode != vm.Success {
+            continue
+        }
+        found++
+        for _, index := range outcome.Patch.Indexes() {
+            fmt.Printf("%d: %v -> %v, accumulator: %d\n", index, program.Instructions[index], outcome.Patch[index],
+                outcome.Accumulator)
+        }
+    }
+
+    fmt.Printf("%d of %d single substitution(s) terminate\n", found, len(patches))
+    if unfinished > 0 {
+        fmt.Printf("%d run(s) stopped by step or time limit\n", unfinished)
+    }
+    if found == 0 {
+        return 1
+    }
+    return 0
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+    "fmt"
+    "io"
+    "os"
+
+    "AdventOfCode20/expense"
+    "AdventOfCode20/internal/cli"
+)
+
+func kSumCommand(args []string, stdout io.Writer) int {
+    flags := tool.NewFlagSet("ksum")
+    k := flags.Int("k", 2, "number of entries adding together the total")
+    total := flags.Int("total", 2020, "total the entries add together")
+    strategy := flags.String("strategy", "hash", "search of the last two entries: hash or two-pointer")
+    duplicates := flags.String("duplicates", "values", "combinations of equal values: values (report once) or "+
+        "entries (report every combination of different lines)")
+    all := flags.Bool("all", false, "list every combination instead of the first one")
+
+    path, ok := cli.SingleOperand(flags, args)
+    if !ok {
+        return 2
+    }
+    options := expense.Options{All: *all}
+    switch *strategy {
+    case "hash":
+        options.Strategy = expense.HashStrategy
+    case "two-pointer":
+        options.Strategy = expense.TwoPointerStrategy
+    default:
+        fmt.Fprintf(os.Stderr, "expenses: unknown strategy %q (use hash or two-pointer)\n", *strategy)
+        return 2
+    }
+    switch *duplicates {
+    case "values":
+        options.Duplicates = expense.DistinctValues
+    case "entries":
+        options.Duplicates = expense.DistinctEntries
+    default:
+        fmt.Fprintf(os.Stderr, "expenses: unknown duplicates policy %q (use values or entries)\n", *duplicates)
+        return 2
+    }
+
+    entries, err := loadReport(path)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "expenses: could not load report: %v\n", err)
+        return 1
+    }
+    solutions := expense.FindKSum(entries, *k, *total, options)
+    if len(solutions) == 0 {
+        fmt.Fprintf(os.Stderr, "expenses: no %d entries add together %d\n", *k, *total)
+        return 1
+    }
+    for _, solution := range solutions {
+        writeEntries(stdout, solution)
+    }
+    return 0
+}
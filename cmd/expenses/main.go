@@ -0,0 +1,69 @@
+package main
+
+import (
+    "fmt"
+    "io"
+    "os"
+    "strings"
+
+    "AdventOfCode20/expense"
+    "AdventOfCode20/input"
+    "AdventOfCode20/internal/cli"
+)
+
+const usage = `Usage:
+    expenses ksum [flags] <report>      find k entries adding together the total and their lines
+    expenses subset [flags] <report>    find the fewest entries of any number adding together the total
+
+Report is the expense report (e.g. Day1/input), "-" reads standard input.
+Run "expenses <command> -h" to list flags of the command.
+`
+
+// Tool describes the command in usage messages of its subcommands.
+var tool = cli.Tool{Name: "expenses", Operands: "<report>"}
+
+func main() {
+    if len(os.Args) < 2 {
+        fmt.Fprint(os.Stderr, usage)
+        os.Exit(2)
+    }
+
+    switch os.Args[1] {
+    case "ksum":
+        os.Exit(kSumCommand(os.Args[2:], os.Stdout))
+    case "subset":
+        os.Exit(subsetCommand(os.Args[2:], os.Stdout))
+    default:
+        fmt.Fprint(os.Stderr, usage)
+        os.Exit(2)
+    }
+}
+
+// Loads all entries of the report. Invalid lines are skipped and summarized on standard error.
+func loadReport(path string) ([]expense.Entry, error) {
+    reader, name := os.Stdin, "<stdin>"
+    if path != cli.Stdio {
+        file, err := os.Open(path)
+        if err != nil {
+            return nil, err
+        }
+        defer file.Close()
+        reader, name = file, path
+    }
+
+    diagnostics := input.NewDiagnostics(name, input.Lenient)
+    entries, err := expense.Load(reader, diagnostics.Handle)
+    input.WriteSummary(os.Stderr, diagnostics.Diagnostics())
+    return entries, err
+}
+
+// Writes the entries adding together the total with their lines, followed by their product (the puzzle answer), e.g.
+// "1721 (line 1) + 299 (line 4) = 2020, product 514579".
+func writeEntries(w io.Writer, entries []expense.Entry) {
+    terms := make([]string, len(entries))
+    for i, e := range entries {
+        terms[i] = fmt.Sprintf("%d (line %d)", e.Value, e.Line)
+    }
+    fmt.Fprintf(w, "%s = %d, product %d\n", strings.Join(terms, " + "), expense.Sum(entries),
+        expense.Product(entries))
+}
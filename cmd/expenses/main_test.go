@@ -0,0 +1,62 @@
+package main
+
+import (
+    "bytes"
+    "os"
+    "path/filepath"
+    "testing"
+)
+
+// Writes the report of the puzzle example into a temporary file.
+func exampleReport(t *testing.T) string {
+    t.Helper()
+    path := filepath.Join(t.TempDir(), "report")
+    if err := os.WriteFile(path, []byte("1721\n979\n366\n299\n675\n1456\n"), 0o644); err != nil {
+        t.Fatal(err)
+    }
+    return path
+}
+
+func TestKSumCommand(t *testing.T) {
+    path := exampleReport(t)
+    for _, test := range []struct {
+        args     []string
+        expected string
+    }{
+        {[]string{path}, "299 (line 4) + 1721 (line 1) = 2020, product 514579\n"},
+        {[]string{path, "-k", "3", "-strategy", "two-pointer"},
+            "366 (line 3) + 675 (line 5) + 979 (line 2) = 2020, product 241861950\n"},
+        {[]string{"-total", "1345", "-all", path}, "366 (line 3) + 979 (line 2) = 1345, product 358314\n"},
+    } {
+        var output bytes.Buffer
+        code := kSumCommand(test.args, &output)
+        if code != 0 || output.String() != test.expected {
+            t.Errorf("%v: got %q (exit %d), want %q", test.args, output.String(), code, test.expected)
+        }
+    }
+}
+
+func TestSubsetCommand(t *testing.T) {
+    path := exampleReport(t)
+    for _, test := range []struct {
+        total    string
+        expected string
+    }{
+        {"2020", "1721 (line 1) + 299 (line 4) = 2020, product 514579\n"},
+        {"979", "979 (line 2) = 979, product 979\n"},
+        {"1340", "366 (line 3) + 299 (line 4) + 675 (line 5) = 1340, product 73867950\n"},
+        {"5496", "1721 (line 1) + 979 (line 2) + 366 (line 3) + 299 (line 4) + 675 (line 5) + 1456 (line 6) = 5496, " +
+            "product 181209509017336800\n"},
+    } {
+        var output bytes.Buffer
+        code := subsetCommand([]string{"-total", test.total, path}, &output)
+        if code != 0 || output.String() != test.expected {
+            t.Errorf("total %s: got %q (exit %d), want %q", test.total, output.String(), code, test.expected)
+        }
+    }
+
+    var output bytes.Buffer
+    if code := subsetCommand([]string{"-total", "1", path}, &output); code != 1 || output.Len() != 0 {
+        t.Errorf("total 1: got %q (exit %d), want no subset", output.String(), code)
+    }
+}
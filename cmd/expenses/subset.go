@@ -0,0 +1,37 @@
+package main
+
+import (
+    "fmt"
+    "io"
+    "os"
+
+    "AdventOfCode20/expense"
+    "AdventOfCode20/internal/cli"
+)
+
+func subsetCommand(args []string, stdout io.Writer) int {
+    flags := tool.NewFlagSet("subset")
+    total := flags.Int("total", 2020, "total the entries add together")
+
+    path, ok := cli.SingleOperand(flags, args)
+    if !ok {
+        return 2
+    }
+    entries, err := loadReport(path)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "expenses: could not load report: %v\n", err)
+        return 1
+    }
+
+    subset, found, err := expense.FindSmallestSubset(entries, *total)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "expenses: %v\n", err)
+        return 1
+    }
+    if !found {
+        fmt.Fprintf(os.Stderr, "expenses: no entries add together %d\n", *total)
+        return 1
+    }
+    writeEntries(stdout, subset)
+    return 0
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+    "fmt"
+    "os"
+
+    "AdventOfCode20/internal/cli"
+    "AdventOfCode20/password"
+)
+
+func auditCommand(args []string) int {
+    flags := tool.NewFlagSet("audit")
+    policiesPath := flags.String("policies", "", "JSON file with policy definitions (default: both puzzle policies)")
+    format := flags.String("format", "markdown", "report format: markdown or json")
+
+    path, ok := cli.SingleOperand(flags, args)
+    if !ok {
+        return 2
+    }
+    if *format != "markdown" && *format != "json" {
+        fmt.Fprintf(os.Stderr, "passwords: unknown report format %q (use markdown or json)\n", *format)
+        return 2
+    }
+    policies, err := loadPolicies(*policiesPath)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "passwords: could not load policies: %v\n", err)
+        return 2
+    }
+    records, err := loadDatabase(path)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "passwords: could not load database: %v\n", err)
+        return 1
+    }
+
+    audit := password.NewAudit(records, policies)
+    if *format == "json" {
+        err = audit.WriteJSON(os.Stdout)
+    } else {
+        err = audit.WriteMarkdown(os.Stdout)
+    }
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "passwords: could not write report: %v\n", err)
+        return 1
+    }
+    return 0
+}
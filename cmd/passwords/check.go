@@ -0,0 +1,53 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "os"
+    "runtime"
+
+    "AdventOfCode20/input"
+    "AdventOfCode20/internal/cli"
+    "AdventOfCode20/password"
+)
+
+func checkCommand(args []string) int {
+    flags := tool.NewFlagSet("check")
+    policiesPath := flags.String("policies", "", "JSON file with policy definitions (default: both puzzle policies)")
+    workers := flags.Int("workers", runtime.NumCPU(), "number of goroutines parsing and checking the records")
+
+    path, ok := cli.SingleOperand(flags, args)
+    if !ok {
+        return 2
+    }
+    policies, err := loadPolicies(*policiesPath)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "passwords: could not load policies: %v\n", err)
+        return 2
+    }
+    file, name, err := openDatabase(path)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "passwords: could not load database: %v\n", err)
+        return 1
+    }
+    defer file.Close()
+
+    // Records are streamed from the database right into the report, so the database is never held in memory.
+    diagnostics := input.NewDiagnostics(name, input.Lenient)
+    report := password.NewReport(os.Stdout, policies)
+    err = password.Stream(context.Background(), file, policies, *workers, diagnostics.Handle,
+        func(record password.Record, violations []password.Violation) error {
+            report.Add(record, violations)
+            return nil
+        })
+    input.WriteSummary(os.Stderr, diagnostics.Diagnostics())
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "passwords: could not load database: %v\n", err)
+        return 1
+    }
+    if err := report.Close(); err != nil {
+        fmt.Fprintf(os.Stderr, "passwords: could not write report: %v\n", err)
+        return 1
+    }
+    return 0
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+    "fmt"
+    "os"
+
+    "AdventOfCode20/input"
+    "AdventOfCode20/internal/cli"
+    "AdventOfCode20/password"
+)
+
+const usage = `Usage:
+    passwords check [flags] <database>    report records failing the password policies
+    passwords audit [flags] <database>    export statistics of the database in Markdown or JSON
+
+Database is the file of password records (e.g. Day2/input), "-" reads
+standard input. Run "passwords <command> -h" to list flags of the command.
+`
+
+// Tool describes the command in usage messages of its subcommands.
+var tool = cli.Tool{Name: "passwords", Operands: "<database>"}
+
+func main() {
+    if len(os.Args) < 2 {
+        fmt.Fprint(os.Stderr, usage)
+        os.Exit(2)
+    }
+
+    switch os.Args[1] {
+    case "check":
+        os.Exit(checkCommand(os.Args[2:]))
+    case "audit":
+        os.Exit(auditCommand(os.Args[2:]))
+    default:
+        fmt.Fprint(os.Stderr, usage)
+        os.Exit(2)
+    }
+}
+
+// Opens the database file or standard input. Name of the input is used in diagnostics.
+func openDatabase(path string) (*os.File, string, error) {
+    if path == cli.Stdio {
+        return os.Stdin, "<stdin>", nil
+    }
+    file, err := os.Open(path)
+    return file, path, err
+}
+
+// Loads all records of the database. Invalid lines are skipped and summarized on standard error.
+func loadDatabase(path string) ([]password.Record, error) {
+    file, name, err := openDatabase(path)
+    if err != nil {
+        return nil, err
+    }
+    defer file.Close()
+
+    diagnostics := input.NewDiagnostics(name, input.Lenient)
+    records, err := password.Load(file, diagnostics.Handle)
+    input.WriteSummary(os.Stderr, diagnostics.Diagnostics())
+    return records, err
+}
+
+// Returns policies of the config file, or the built-in policies of the puzzle if no file is given.
+func loadPolicies(path string) ([]password.Policy, error) {
+    if path == "" {
+        return []password.Policy{password.SledRental(), password.Toboggan()}, nil
+    }
+
+    file, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer file.Close()
+    return password.LoadPolicies(file)
+}
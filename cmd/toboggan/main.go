@@ -0,0 +1,96 @@
+package main
+
+import (
+    "fmt"
+    "os"
+    "strings"
+
+    "AdventOfCode20/input"
+    "AdventOfCode20/internal/cli"
+    "AdventOfCode20/toboggan"
+)
+
+const usage = `Usage:
+    toboggan ride [flags] <map>      count trees encountered on the rides of the slopes
+    toboggan search [flags] <map>    find the slope with the lowest or highest share of trees on its rows
+    toboggan render [flags] <map>    draw the rides over the map as text or PNG image
+
+Map is the slope map (e.g. Day3/input), "-" reads standard input. Slopes are given as "right,down", right movement
+may be negative or fractional, e.g. "3,1", "-1,2" or "1/2,1".
+Run "toboggan <command> -h" to list flags of the command.
+`
+
+// Tool describes the command in usage messages of its subcommands.
+var tool = cli.Tool{Name: "toboggan", Operands: "<map>"}
+
+func main() {
+    if len(os.Args) < 2 {
+        fmt.Fprint(os.Stderr, usage)
+        os.Exit(2)
+    }
+
+    switch os.Args[1] {
+    case "ride":
+        os.Exit(rideCommand(os.Args[2:], os.Stdout))
+    case "search":
+        os.Exit(searchCommand(os.Args[2:], os.Stdout))
+    case "render":
+        os.Exit(renderCommand(os.Args[2:], os.Stdout))
+    default:
+        fmt.Fprint(os.Stderr, usage)
+        os.Exit(2)
+    }
+}
+
+// Loads the slope map. Invalid rows are skipped and summarized on standard error.
+func loadMap(path string) (*toboggan.Map, error) {
+    reader, name := os.Stdin, "<stdin>"
+    if path != cli.Stdio {
+        file, err := os.Open(path)
+        if err != nil {
+            return nil, err
+        }
+        defer file.Close()
+        reader, name = file, path
+    }
+
+    diagnostics := input.NewDiagnostics(name, input.Lenient)
+    slopeMap, err := toboggan.Load(reader, diagnostics.Handle)
+    input.WriteSummary(os.Stderr, diagnostics.Diagnostics())
+    return slopeMap, err
+}
+
+// Slopes of the second part of the puzzle, the default slopes of the rides.
+const puzzleSlopes = "1,1 3,1 5,1 7,1 1,2"
+
+// Parses space separated list of slopes.
+func parseSlopes(text string) ([]toboggan.Slope, error) {
+    var slopes []toboggan.Slope
+    for _, field := range strings.Fields(text) {
+        s, err := toboggan.ParseSlope(field)
+        if err != nil {
+            return nil, err
+        }
+        slopes = append(slopes, s)
+    }
+    if len(slopes) == 0 {
+        return nil, fmt.Errorf("no slope given")
+    }
+    return slopes, nil
+}
+
+// Returns the rounding of given name (down, nearest or skip).
+func parseRounding(name string) (toboggan.Rounding, error) {
+    switch name {
+    case "down":
+        return toboggan.RoundDown, nil
+    case "nearest":
+        return toboggan.RoundNearest, nil
+    case "skip":
+        return toboggan.SkipBetween, nil
+    }
+    return 0, fmt.Errorf("unknown rounding %q (use down, nearest or skip)", name)
+}
+
+// Help of the rounding flag shared by the commands.
+const roundingUsage = "column passed between two columns: down (the left one), nearest or skip (neither)"
@@ -0,0 +1,94 @@
+package main
+
+import (
+    "bytes"
+    "image/png"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+// Example map of the puzzle.
+var exampleMap = filepath.Join("..", "..", "Day3", "example")
+
+func TestRideCommand(t *testing.T) {
+    for _, test := range []struct {
+        args     []string
+        expected string
+    }{
+        {[]string{exampleMap}, "right 1, down 1: 2 trees\nright 3, down 1: 7 trees\nright 5, down 1: 3 trees\n" +
+            "right 7, down 1: 4 trees\nright 1, down 2: 2 trees\nproduct 336\n"},
+        {[]string{exampleMap, "-slopes", "1/2,1 -8,1", "-rounding", "skip"},
+            "right 1/2, down 1: 2 trees\nright -8, down 1: 7 trees\nproduct 14\n"},
+    } {
+        var output bytes.Buffer
+        code := rideCommand(test.args, &output)
+        if code != 0 || output.String() != test.expected {
+            t.Errorf("%v: got %q (exit %d), want %q", test.args, output.String(), code, test.expected)
+        }
+    }
+
+    var output bytes.Buffer
+    if code := rideCommand([]string{"-slopes", "3,0", exampleMap}, &output); code != 2 || output.Len() != 0 {
+        t.Errorf("invalid slope: got %q (exit %d), want usage error", output.String(), code)
+    }
+}
+
+func TestSearchCommand(t *testing.T) {
+    for _, test := range []struct {
+        args     []string
+        expected string
+    }{
+        {[]string{exampleMap, "-max-down", "3"}, "right 5, down 2: 0 trees on 6 rows\n"},
+        {[]string{"-goal", "most", exampleMap}, "right 6, down 2: 4 trees on 6 rows\n"},
+    } {
+        var output bytes.Buffer
+        code := searchCommand(test.args, &output)
+        if code != 0 || output.String() != test.expected {
+            t.Errorf("%v: got %q (exit %d), want %q", test.args, output.String(), code, test.expected)
+        }
+    }
+
+    var output bytes.Buffer
+    if code := searchCommand([]string{"-min-right", "2", "-max-right", "1", exampleMap}, &output); code != 2 {
+        t.Errorf("empty bounds: got %q (exit %d), want usage error", output.String(), code)
+    }
+}
+
+func TestRenderCommand(t *testing.T) {
+    var output bytes.Buffer
+    if code := renderCommand([]string{exampleMap}, &output); code != 0 {
+        t.Fatalf("got exit %d", code)
+    }
+    lines := strings.Split(output.String(), "\n")
+    if lines[1] != "#..O#...#..#...#...#..#...#...#" || lines[11] != "ride 1: right 3, down 1, 7 trees" {
+        t.Errorf("got rows %q and legend %q", lines[1], lines[11])
+    }
+
+    path := filepath.Join(t.TempDir(), "rides.png")
+    output.Reset()
+    if code := renderCommand([]string{"-png", path, "-size", "4", "-slopes", "1,1 -1,2", exampleMap},
+        &output); code != 0 || output.Len() != 0 {
+        t.Fatalf("got %q (exit %d), want image in the file", output.String(), code)
+    }
+    file, err := os.Open(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer file.Close()
+    img, err := png.Decode(file)
+    if err != nil {
+        t.Fatal(err)
+    }
+    // Map spans columns -5 to 10.
+    if size := img.Bounds().Size(); size.X != 16*4 || size.Y != 11*4 {
+        t.Errorf("got image %v, want 64x44", size)
+    }
+
+    // Image of the ride far to the right would not fit into memory.
+    if code := renderCommand([]string{"-png", "-", "-size", "100", "-slopes", "1000,1", exampleMap},
+        &output); code != 1 {
+        t.Errorf("got exit %d of too large image, want 1", code)
+    }
+}
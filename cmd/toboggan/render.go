@@ -0,0 +1,61 @@
+package main
+
+import (
+    "fmt"
+    "io"
+    "os"
+
+    "AdventOfCode20/internal/cli"
+    "AdventOfCode20/toboggan"
+)
+
+// Draws the rides over the map, as text to standard output or as PNG image.
+func renderCommand(args []string, stdout io.Writer) int {
+    flags := tool.NewFlagSet("render")
+    slopesText := flags.String("slopes", "3,1", "space separated slopes of the rides")
+    roundingName := flags.String("rounding", "down", roundingUsage)
+    pngPath := flags.String("png", "", "write PNG image into the file instead of text (\"-\" for standard output)")
+    colors := flags.Bool("color", false, "tell the rides apart by ANSI colors in text")
+    size := flags.Int("size", 8, "size of a square of the PNG image in pixels (at least 3)")
+
+    path, ok := cli.SingleOperand(flags, args)
+    if !ok {
+        return 2
+    }
+    slopes, err := parseSlopes(*slopesText)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "toboggan: %v\n", err)
+        return 2
+    }
+    rounding, err := parseRounding(*roundingName)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "toboggan: %v\n", err)
+        return 2
+    }
+
+    slopeMap, err := loadMap(path)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "toboggan: could not load map: %v\n", err)
+        return 1
+    }
+    rides := make([]toboggan.Ride, len(slopes))
+    for i, s := range slopes {
+        rides[i] = slopeMap.Ride(s, rounding)
+    }
+
+    switch *pngPath {
+    case "":
+        err = slopeMap.WriteASCII(stdout, rides, *colors)
+    case cli.Stdio:
+        err = slopeMap.WritePNG(stdout, rides, *size)
+    default:
+        err = cli.WriteOutput(*pngPath, func(w io.Writer) error {
+            return slopeMap.WritePNG(w, rides, *size)
+        })
+    }
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "toboggan: could not render rides: %v\n", err)
+        return 1
+    }
+    return 0
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+    "fmt"
+    "io"
+    "os"
+
+    "AdventOfCode20/internal/cli"
+)
+
+// Counts trees of every ride, followed by their product (the puzzle answer for the default slopes).
+func rideCommand(args []string, stdout io.Writer) int {
+    flags := tool.NewFlagSet("ride")
+    slopesText := flags.String("slopes", puzzleSlopes, "space separated slopes of the rides")
+    roundingName := flags.String("rounding", "down", roundingUsage)
+
+    path, ok := cli.SingleOperand(flags, args)
+    if !ok {
+        return 2
+    }
+    slopes, err := parseSlopes(*slopesText)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "toboggan: %v\n", err)
+        return 2
+    }
+    rounding, err := parseRounding(*roundingName)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "toboggan: %v\n", err)
+        return 2
+    }
+
+    slopeMap, err := loadMap(path)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "toboggan: could not load map: %v\n", err)
+        return 1
+    }
+    product := 1
+    for i, trees := range slopeMap.Evaluate(slopes, rounding) {
+        fmt.Fprintf(stdout, "%v: %d trees\n", slopes[i], trees)
+        product *= trees
+    }
+    fmt.Fprintf(stdout, "product %d\n", product)
+    return 0
+}
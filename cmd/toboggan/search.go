@@ -0,0 +1,56 @@
+package main
+
+import (
+    "fmt"
+    "io"
+    "os"
+
+    "AdventOfCode20/internal/cli"
+    "AdventOfCode20/toboggan"
+)
+
+// Searches the slopes within the bounds for the lowest or highest share of trees on the rows of the ride.
+func searchCommand(args []string, stdout io.Writer) int {
+    flags := tool.NewFlagSet("search")
+    var bounds toboggan.Bounds
+    flags.IntVar(&bounds.MinRight, "min-right", 0, "minimum right movement (negative goes left)")
+    flags.IntVar(&bounds.MaxRight, "max-right", 7, "maximum right movement")
+    flags.IntVar(&bounds.MaxDown, "max-down", 2, "maximum down movement")
+    flags.IntVar(&bounds.MaxDenominator, "max-denominator", 1, "maximum denominator of fractional right movement "+
+        "(1 tries whole numbers only)")
+    goalName := flags.String("goal", "fewest", "share of trees to look for: fewest or most")
+    roundingName := flags.String("rounding", "down", roundingUsage)
+
+    path, ok := cli.SingleOperand(flags, args)
+    if !ok {
+        return 2
+    }
+    var goal toboggan.Goal
+    switch *goalName {
+    case "fewest":
+        goal = toboggan.FewestTrees
+    case "most":
+        goal = toboggan.MostTrees
+    default:
+        fmt.Fprintf(os.Stderr, "toboggan: unknown goal %q (use fewest or most)\n", *goalName)
+        return 2
+    }
+    rounding, err := parseRounding(*roundingName)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "toboggan: %v\n", err)
+        return 2
+    }
+
+    slopeMap, err := loadMap(path)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "toboggan: could not load map: %v\n", err)
+        return 1
+    }
+    result, err := slopeMap.FindSlope(bounds, goal, rounding)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "toboggan: %v\n", err)
+        return 2
+    }
+    fmt.Fprintf(stdout, "%v: %d trees on %d rows\n", result.Slope, result.Trees, result.Rows)
+    return 0
+}
@@ -0,0 +1,18 @@
+// Package days links solutions of all days into the binary. Every day registers itself with the aoc package once
+// imported, so importing this package for side effects is all the runner needs.
+package days
+
+import (
+    _ "AdventOfCode20/Day1"
+    _ "AdventOfCode20/Day10"
+    _ "AdventOfCode20/Day11"
+    _ "AdventOfCode20/Day12"
+    _ "AdventOfCode20/Day2"
+    _ "AdventOfCode20/Day3"
+    _ "AdventOfCode20/Day4"
+    _ "AdventOfCode20/Day5"
+    _ "AdventOfCode20/Day6"
+    _ "AdventOfCode20/Day7"
+    _ "AdventOfCode20/Day8"
+    _ "AdventOfCode20/Day9"
+)
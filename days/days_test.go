@@ -0,0 +1,106 @@
+package days
+
+import (
+    "encoding/json"
+    "fmt"
+    "os"
+    "path/filepath"
+    "testing"
+
+    "AdventOfCode20/aoc"
+    "AdventOfCode20/input"
+)
+
+// Location of the repository root (inputs are stored relatively to it) from the package directory.
+const rootDir = ".."
+
+// Golden answer of a single input. Answers of parts that are not defined for the input (e.g. puzzle examples which
+// only illustrate the first part) are left out.
+type goldenAnswer struct {
+    Day   int         `json:"day"`
+    Input string      `json:"input"`
+    Part1 *aoc.Answer `json:"part1"`
+    Part2 *aoc.Answer `json:"part2"`
+}
+
+func loadGoldenAnswers(t testing.TB) []goldenAnswer {
+    data, err := os.ReadFile(filepath.Join("testdata", "answers.json"))
+    if err != nil {
+        t.Fatalf("could not read answers file: %v", err)
+    }
+
+    var answers []goldenAnswer
+    if err := json.Unmarshal(data, &answers); err != nil {
+        t.Fatalf("could not parse answers file: %v", err)
+    }
+    return answers
+}
+
+func TestGoldenAnswers(t *testing.T) {
+    for _, golden := range loadGoldenAnswers(t) {
+        golden := golden
+        t.Run(golden.Input, func(t *testing.T) {
+            data, err := aoc.ReadInput(golden.Day, filepath.Join(rootDir, filepath.FromSlash(golden.Input)))
+            if err != nil {
+                t.Fatal(err)
+            }
+
+            results, err := aoc.Solve(golden.Day, data, input.FailOnError)
+            if err != nil {
+                t.Fatal(err)
+            }
+
+            for _, result := range results {
+                expected := golden.Part1
+                if result.Part == 2 {
+                    expected = golden.Part2
+                }
+                if expected == nil {
+                    continue
+                }
+
+                if result.Err != nil {
+                    t.Errorf("part %d: unexpected error: %v", result.Part, result.Err)
+                } else if result.Answer != *expected {
+                    t.Errorf("part %d: got %v, want %v", result.Part, result.Answer, *expected)
+                }
+            }
+        })
+    }
+}
+
+// Every registered day has to be covered by golden answers of its real puzzle input.
+func TestGoldenAnswersCoverAllDays(t *testing.T) {
+    covered := make(map[int]bool)
+    for _, golden := range loadGoldenAnswers(t) {
+        if filepath.Base(golden.Input) == "input" && golden.Part1 != nil && golden.Part2 != nil {
+            covered[golden.Day] = true
+        }
+    }
+
+    for _, day := range aoc.Days() {
+        if !covered[day] {
+            t.Errorf("day %d has no golden answers for its puzzle input", day)
+        }
+    }
+}
+
+// Benchmarks parsing and solving of every registered day with its puzzle input.
+func BenchmarkDays(b *testing.B) {
+    for _, day := range aoc.Days() {
+        day := day
+        data, err := aoc.ReadInput(day, aoc.DefaultInputPath(rootDir, day))
+        if err != nil {
+            b.Fatal(err)
+        }
+
+        b.Run(fmt.Sprintf("Day%d", day), func(b *testing.B) {
+            b.ReportAllocs()
+            for i := 0; i < b.N; i++ {
+                if _, err := aoc.Solve(day, data, input.FailOnError); err != nil {
+                    b.Fatal(err)
+                }
+            }
+        })
+    }
+}
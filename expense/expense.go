@@ -0,0 +1,35 @@
+// Package expense implements the expense report from Day 1: entries keeping the lines they come from, and searches for
+// entries adding together given total, both of a fixed number of entries (k-sum) and of any number (subset sum).
+package expense
+
+import (
+    "io"
+    "strings"
+
+    "AdventOfCode20/input"
+)
+
+// Entry of the expense report together with the number of its line in the input.
+type Entry struct {
+    Value int
+    Line  int
+}
+
+// Load reads all entries of the report, one number per line. Blank lines are ignored, invalid lines are reported to
+// the error handler.
+func Load(reader io.Reader, onError input.ErrorHandler) ([]Entry, error) {
+    var entries []Entry
+    err := input.EachLine(reader, func(line input.Line) error {
+        if strings.TrimSpace(line.Text) == "" {
+            return nil
+        }
+
+        value, err := input.ParseInt(line)
+        if err != nil {
+            return onError(err)
+        }
+        entries = append(entries, Entry{Value: value, Line: line.Number})
+        return nil
+    })
+    return entries, err
+}
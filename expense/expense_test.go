@@ -0,0 +1,194 @@
+package expense
+
+import (
+    "fmt"
+    "math"
+    "math/rand"
+    "reflect"
+    "sort"
+    "testing"
+)
+
+func expenses(values ...int) []Entry {
+    var result []Entry
+    for i, value := range values {
+        result = append(result, Entry{Value: value, Line: i + 1})
+    }
+    return result
+}
+
+// Describes the solutions by the values (or lines) of their entries, in sorted order, so the results of different
+// searches can be compared.
+func describe(solutions [][]Entry, byLines bool) []string {
+    var descriptions []string
+    for _, solution := range solutions {
+        var keys []int
+        for _, e := range solution {
+            if byLines {
+                keys = append(keys, e.Line)
+            } else {
+                keys = append(keys, e.Value)
+            }
+        }
+        sort.Ints(keys)
+        descriptions = append(descriptions, fmt.Sprint(keys))
+    }
+    sort.Strings(descriptions)
+    return descriptions
+}
+
+// Finds all combinations of k entries adding together given total by trying every combination of indexes.
+func bruteForceKSum(entries []Entry, k int, total int) [][]Entry {
+    var solutions [][]Entry
+    var choose func(start int, chosen []Entry)
+    choose = func(start int, chosen []Entry) {
+        if len(chosen) == k {
+            if Sum(chosen) == total {
+                solutions = append(solutions, append([]Entry(nil), chosen...))
+            }
+            return
+        }
+        for i := start; i < len(entries); i++ {
+            choose(i+1, append(chosen, entries[i]))
+        }
+    }
+    choose(0, nil)
+    return solutions
+}
+
+// Removes repeated descriptions from the sorted list.
+func unique(descriptions []string) []string {
+    var result []string
+    for i, description := range descriptions {
+        if i == 0 || descriptions[i-1] != description {
+            result = append(result, description)
+        }
+    }
+    return result
+}
+
+func TestKSumMatchesBruteForce(t *testing.T) {
+    random := rand.New(rand.NewSource(2020))
+    for round := 0; round < 300; round++ {
+        // Small range of values makes duplicates (and so repeated solutions) common.
+        values := make([]int, random.Intn(12))
+        for i := range values {
+            values[i] = random.Intn(21) - 5
+        }
+        entries := expenses(values...)
+        original := fmt.Sprint(entries)
+        k := random.Intn(5)
+        total := random.Intn(31) - 5
+        expected := bruteForceKSum(entries, k, total)
+
+        for _, strategy := range []Strategy{HashStrategy, TwoPointerStrategy} {
+            name := fmt.Sprintf("strategy %d, %d-sum of %v to %d", strategy, k, values, total)
+
+            all := FindKSum(entries, k, total, Options{Strategy: strategy, Duplicates: DistinctEntries, All: true})
+            if got, want := describe(all, true), describe(expected, true); !reflect.DeepEqual(got, want) {
+                t.Fatalf("%s: got entries %v, want %v", name, got, want)
+            }
+
+            all = FindKSum(entries, k, total, Options{Strategy: strategy, Duplicates: DistinctValues, All: true})
+            if got, want := describe(all, false), unique(describe(expected, false)); !reflect.DeepEqual(got, want) {
+                t.Fatalf("%s: got values %v, want %v", name, got, want)
+            }
+
+            first := FindKSum(entries, k, total, Options{Strategy: strategy})
+            if len(expected) == 0 && len(first) != 0 || len(expected) > 0 && len(first) != 1 {
+                t.Fatalf("%s: got first %v of %d solutions", name, first, len(expected))
+            }
+            if len(first) == 1 && (len(first[0]) != k || Sum(first[0]) != total) {
+                t.Fatalf("%s: got invalid first %v", name, first)
+            }
+        }
+
+        if fmt.Sprint(entries) != original {
+            t.Fatalf("search reordered the entries %v to %v", original, entries)
+        }
+    }
+}
+
+func TestKSumReportsLinesOfDuplicates(t *testing.T) {
+    // Single 1010 cannot be used twice.
+    if solutions := FindKSum(expenses(1721, 1010, 299), 2, 2020, Options{All: true}); len(solutions) != 1 {
+        t.Errorf("got %v, want only 1721 + 299", solutions)
+    }
+
+    entries := expenses(1010, 5, 1010, 2015, 1010)
+    for _, strategy := range []Strategy{HashStrategy, TwoPointerStrategy} {
+        options := Options{Strategy: strategy, Duplicates: DistinctEntries, All: true}
+        got := describe(FindKSum(entries, 2, 2020, options), true)
+        want := []string{"[1 3]", "[1 5]", "[2 4]", "[3 5]"}
+        if !reflect.DeepEqual(got, want) {
+            t.Errorf("strategy %d: got lines %v, want %v", strategy, got, want)
+        }
+
+        // Equal values are represented by their first entries.
+        options.Duplicates = DistinctValues
+        got = describe(FindKSum(entries, 2, 2020, options), true)
+        want = []string{"[1 3]", "[2 4]"}
+        if !reflect.DeepEqual(got, want) {
+            t.Errorf("strategy %d: got lines %v, want %v", strategy, got, want)
+        }
+    }
+}
+
+func TestSmallestSubsetMatchesBruteForce(t *testing.T) {
+    random := rand.New(rand.NewSource(1))
+    for round := 0; round < 300; round++ {
+        // Every other round has negative values, which only meet in the middle can handle.
+        low := 0
+        if round%2 == 1 {
+            low = -10
+        }
+        values := make([]int, random.Intn(10))
+        for i := range values {
+            values[i] = low + random.Intn(30)
+        }
+        entries := expenses(values...)
+        total := random.Intn(60)
+
+        smallest := 0
+        for k := 1; k <= len(entries) && smallest == 0; k++ {
+            if len(bruteForceKSum(entries, k, total)) > 0 {
+                smallest = k
+            }
+        }
+
+        subset, found, err := FindSmallestSubset(entries, total)
+        if err != nil {
+            t.Fatal(err)
+        }
+        // Meet in the middle is checked on its own as well, the search above prefers the table when it can.
+        middle, middleFound := smallestSubsetInMiddle(entries, total)
+        for _, tc := range []struct {
+            subset []Entry
+            found  bool
+        }{{subset, found}, {middle, middleFound}} {
+            if tc.found != (smallest > 0) || len(tc.subset) != smallest || tc.found && Sum(tc.subset) != total {
+                t.Fatalf("subset of %v to %d: got %v (%v), want %d entries", values, total, tc.subset, tc.found,
+                    smallest)
+            }
+            used := make(map[int]bool)
+            for _, e := range tc.subset {
+                if used[e.Line] {
+                    t.Fatalf("subset of %v to %d: got %v using line %d twice", values, total, tc.subset, e.Line)
+                }
+                used[e.Line] = true
+            }
+        }
+    }
+}
+
+func TestSmallestSubsetOfLargeTotal(t *testing.T) {
+    // Size of the table would overflow, large totals have to be met in the middle.
+    if subset, found, err := FindSmallestSubset(expenses(1, 2, 3, 4), 1<<62); err != nil || found {
+        t.Errorf("got %v (%v, %v), want no subset", subset, found, err)
+    }
+    entries := expenses(math.MaxInt-3, 1, 2, 3)
+    subset, found, err := FindSmallestSubset(entries, math.MaxInt)
+    if expected := []Entry{entries[0], entries[3]}; err != nil || !found || !reflect.DeepEqual(subset, expected) {
+        t.Errorf("got %v (%v, %v), want %v", subset, found, err, expected)
+    }
+}
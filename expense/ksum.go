@@ -0,0 +1,195 @@
+package expense
+
+import "sort"
+
+// Strategy of the search for the last two entries of the k-sum; the rest is always chosen by recursion.
+type Strategy int
+
+const (
+    // Hash strategy remembers the entries seen so far and looks up the missing complement.
+    HashStrategy Strategy = iota
+    // Two-pointer strategy walks the sorted entries from both ends towards each other.
+    TwoPointerStrategy
+)
+
+// DuplicatePolicy decides which of the combinations with duplicate values are reported.
+type DuplicatePolicy int
+
+const (
+    // Combinations of the same values are reported only once, no matter which of the equal entries they use.
+    DistinctValues DuplicatePolicy = iota
+    // Every combination of different entries is reported, even if their values repeat an already found combination.
+    DistinctEntries
+)
+
+// Options of the k-sum search. Zero value uses the hash strategy and stops at the first combination.
+type Options struct {
+    Strategy   Strategy
+    Duplicates DuplicatePolicy
+    // All enumerates every combination instead of stopping at the first one.
+    All bool
+}
+
+// FindKSum looks up combinations of k entries which add together given total. Every entry is used at most once, so
+// a value can only repeat in a combination if it appears on more lines. Entries of a combination are ordered by their
+// values (and lines). The search works on a sorted copy, the given entries are left intact.
+func FindKSum(expenses []Entry, k int, total int, options Options) [][]Entry {
+    sorted := append([]Entry(nil), expenses...)
+    sort.Slice(sorted, func(i, j int) bool {
+        if sorted[i].Value != sorted[j].Value {
+            return sorted[i].Value < sorted[j].Value
+        }
+        return sorted[i].Line < sorted[j].Line
+    })
+
+    search := &kSumSearch{expenses: sorted, options: options}
+    search.find(0, k, total, nil)
+    return search.solutions
+}
+
+type kSumSearch struct {
+    expenses  []Entry
+    options   Options
+    solutions [][]Entry
+}
+
+func (s *kSumSearch) done() bool {
+    return !s.options.All && len(s.solutions) > 0
+}
+
+func (s *kSumSearch) add(chosen []Entry, rest ...Entry) {
+    solution := append(append([]Entry(nil), chosen...), rest...)
+    s.solutions = append(s.solutions, solution)
+}
+
+// Chooses k entries from the sorted entries starting on given index, after the already chosen ones.
+func (s *kSumSearch) find(start int, k int, total int, chosen []Entry) {
+    expenses := s.expenses[start:]
+    if k > len(expenses) || k < 0 {
+        return
+    }
+
+    switch k {
+    case 0:
+        if total == 0 {
+            s.add(chosen)
+        }
+        return
+    case 1:
+        i := sort.Search(len(expenses), func(i int) bool { return expenses[i].Value >= total })
+        for ; i < len(expenses) && expenses[i].Value == total && !s.done(); i++ {
+            s.add(chosen, expenses[i])
+            if s.options.Duplicates == DistinctValues {
+                break
+            }
+        }
+        return
+    case 2:
+        if s.options.Strategy == TwoPointerStrategy {
+            s.findPairTwoPointer(expenses, total, chosen)
+        } else {
+            s.findPairHash(expenses, total, chosen)
+        }
+        return
+    }
+
+    // Sums of k smallest and k greatest values bound every sum of k entries, so the search can stop once the total
+    // gets out of them.
+    if Sum(expenses[:k]) > total || Sum(expenses[len(expenses)-k:]) < total {
+        return
+    }
+    for i, e := range expenses {
+        if len(expenses)-i < k || s.done() {
+            return
+        }
+        // The same value on the same position gives combinations of the same values.
+        if s.options.Duplicates == DistinctValues && i > 0 && expenses[i-1].Value == e.Value {
+            continue
+        }
+        s.find(start+i+1, k-1, total-e.Value, append(chosen, e))
+    }
+}
+
+func (s *kSumSearch) findPairTwoPointer(expenses []Entry, total int, chosen []Entry) {
+    low, high := 0, len(expenses)-1
+    for low < high && !s.done() {
+        switch pairTotal := expenses[low].Value + expenses[high].Value; {
+        case pairTotal < total:
+            low++
+        case pairTotal > total:
+            high--
+        default:
+            // Runs of equal values on both ends; all entries of the runs pair with each other.
+            lowEnd, highStart := low, high
+            for lowEnd+1 <= high && expenses[lowEnd+1].Value == expenses[low].Value {
+                lowEnd++
+            }
+            for highStart-1 >= low && expenses[highStart-1].Value == expenses[high].Value {
+                highStart--
+            }
+
+            sameRun := expenses[low].Value == expenses[high].Value
+            switch {
+            case s.options.Duplicates == DistinctValues && sameRun:
+                s.add(chosen, expenses[low], expenses[low+1])
+            case s.options.Duplicates == DistinctValues:
+                s.add(chosen, expenses[low], expenses[highStart])
+            case sameRun:
+                // Both runs are the same one, pair its entries among themselves.
+                for i := low; i <= high && !s.done(); i++ {
+                    for j := i + 1; j <= high && !s.done(); j++ {
+                        s.add(chosen, expenses[i], expenses[j])
+                    }
+                }
+            default:
+                for i := low; i <= lowEnd && !s.done(); i++ {
+                    for j := highStart; j <= high && !s.done(); j++ {
+                        s.add(chosen, expenses[i], expenses[j])
+                    }
+                }
+            }
+            low, high = lowEnd+1, highStart-1
+        }
+    }
+}
+
+func (s *kSumSearch) findPairHash(expenses []Entry, total int, chosen []Entry) {
+    seen := make(map[int][]Entry)
+    found := make(map[int]bool)
+    for _, e := range expenses {
+        if s.done() {
+            return
+        }
+        // Entries are sorted, so the complements seen before never have greater values.
+        complements := seen[total-e.Value]
+        switch {
+        case len(complements) == 0:
+        case s.options.Duplicates == DistinctEntries:
+            for i := 0; i < len(complements) && !s.done(); i++ {
+                s.add(chosen, complements[i], e)
+            }
+        case !found[e.Value]:
+            s.add(chosen, complements[0], e)
+            found[e.Value] = true
+        }
+        seen[e.Value] = append(seen[e.Value], e)
+    }
+}
+
+// Sum adds values of the entries together.
+func Sum(expenses []Entry) int {
+    total := 0
+    for _, e := range expenses {
+        total += e.Value
+    }
+    return total
+}
+
+// Product multiplies values of the entries.
+func Product(expenses []Entry) int {
+    result := 1
+    for _, e := range expenses {
+        result *= e.Value
+    }
+    return result
+}
@@ -0,0 +1,160 @@
+package expense
+
+import "errors"
+
+// Limits of the subset-sum search: the table of dynamic programming has at most maxSubsetTable cells and meet in the
+// middle enumerates all subsets of halves of at most maxSubsetHalf entries.
+const (
+    maxSubsetTable = 1 << 24
+    maxSubsetHalf  = 20
+)
+
+// ErrSubsetTooLarge is returned by FindSmallestSubset for reports it cannot search in reasonable time and memory.
+var ErrSubsetTooLarge = errors.New("expense report is too large for subset-sum search")
+
+// FindSmallestSubset looks up the smallest non-empty subset of entries which add together given total. Subsets of
+// entries with non-negative values are found by dynamic programming over all sums up to the total; when that is not
+// possible (negative values or too large total), subsets of both halves of the report are enumerated and met in the
+// middle.
+// Returns the subset in order of the entries and flag indicating whether the lookup was successful.
+func FindSmallestSubset(expenses []Entry, total int) ([]Entry, bool, error) {
+    nonNegative := total >= 0
+    for _, e := range expenses {
+        if e.Value < 0 {
+            nonNegative = false
+        }
+    }
+
+    // Size of the table is compared by division, multiplication could overflow for large totals.
+    switch {
+    case nonNegative && total < maxSubsetTable/(len(expenses)+1):
+        subset, found := smallestSubsetByTable(expenses, total)
+        return subset, found, nil
+    case (len(expenses)+1)/2 <= maxSubsetHalf:
+        subset, found := smallestSubsetInMiddle(expenses, total)
+        return subset, found, nil
+    default:
+        return nil, false, ErrSubsetTooLarge
+    }
+}
+
+// Dynamic programming over sums: counts[sum] is the size of the smallest subset of already processed entries with
+// the sum, taken[i][sum] records whether the entry i belongs to it.
+func smallestSubsetByTable(expenses []Entry, total int) ([]Entry, bool) {
+    // Non-empty subset of non-negative entries adds up to zero only if it contains zero.
+    if total == 0 {
+        for _, e := range expenses {
+            if e.Value == 0 {
+                return []Entry{e}, true
+            }
+        }
+        return nil, false
+    }
+
+    const unreachable = -1
+    counts := make([]int, total+1)
+    for sum := range counts {
+        counts[sum] = unreachable
+    }
+    counts[0] = 0
+
+    taken := make([][]bool, len(expenses))
+    for i, e := range expenses {
+        taken[i] = make([]bool, total+1)
+        // Sums go down, so every entry is used at most once.
+        for sum := total; sum >= e.Value; sum-- {
+            previous := counts[sum-e.Value]
+            if previous == unreachable {
+                continue
+            }
+            if counts[sum] == unreachable || previous+1 < counts[sum] {
+                counts[sum] = previous + 1
+                taken[i][sum] = true
+            }
+        }
+    }
+    if counts[total] == unreachable {
+        return nil, false
+    }
+
+    var subset []Entry
+    sum := total
+    for i := len(expenses) - 1; i >= 0; i-- {
+        if taken[i][sum] {
+            subset = append([]Entry{expenses[i]}, subset...)
+            sum -= expenses[i].Value
+        }
+    }
+    return subset, true
+}
+
+// Subset of a half of the report, members are given by bits of the mask.
+type halfSubset struct {
+    sum   int
+    count int
+    mask  int
+}
+
+// Meet in the middle: the smallest non-empty subset of the second half is remembered for every sum, then every subset
+// of the first half is completed either by nothing or by the subset of the complement sum.
+func smallestSubsetInMiddle(expenses []Entry, total int) ([]Entry, bool) {
+    first, second := expenses[:len(expenses)/2], expenses[len(expenses)/2:]
+
+    smallest := make(map[int]halfSubset)
+    for _, subset := range enumerateSubsets(second)[1:] {
+        if best, ok := smallest[subset.sum]; !ok || subset.count < best.count {
+            smallest[subset.sum] = subset
+        }
+    }
+
+    var bestFirst, bestSecond halfSubset
+    found := false
+    consider := func(subset, complement halfSubset) {
+        count := subset.count + complement.count
+        if count > 0 && (!found || count < bestFirst.count+bestSecond.count) {
+            bestFirst, bestSecond, found = subset, complement, true
+        }
+    }
+    for _, subset := range enumerateSubsets(first) {
+        if subset.sum == total {
+            consider(subset, halfSubset{})
+        }
+        if complement, ok := smallest[total-subset.sum]; ok {
+            consider(subset, complement)
+        }
+    }
+    if !found {
+        return nil, false
+    }
+
+    var subset []Entry
+    for i, e := range first {
+        if bestFirst.mask&(1<<i) != 0 {
+            subset = append(subset, e)
+        }
+    }
+    for i, e := range second {
+        if bestSecond.mask&(1<<i) != 0 {
+            subset = append(subset, e)
+        }
+    }
+    return subset, true
+}
+
+func enumerateSubsets(expenses []Entry) []halfSubset {
+    subsets := make([]halfSubset, 1<<len(expenses))
+    for mask := 1; mask < len(subsets); mask++ {
+        // Every subset extends the one without its lowest member.
+        lowest := 0
+        for mask&(1<<lowest) == 0 {
+            lowest++
+        }
+        previous := subsets[mask&(mask-1)]
+        subsets[mask] = halfSubset{
+            sum:   previous.sum + expenses[lowest].Value,
+            count: previous.count + 1,
+            mask:  mask,
+        }
+    }
+    return subsets
+}
@@ -0,0 +1,3 @@
+module AdventOfCode20
+
+go 1.18
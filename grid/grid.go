@@ -0,0 +1,238 @@
+// Package grid provides dense two-dimensional grid of typed cells, which is the base of all map-like puzzles.
+// Cells are stored in a single slice row by row, the grid can be either bounded or wrapped around in both axes
+// (toroidal).
+package grid
+
+import (
+    "bufio"
+    "fmt"
+    "io"
+    "strings"
+
+    "AdventOfCode20/input"
+)
+
+// Point is a position within the grid, or a vector between two positions. X grows to the right, Y grows downwards.
+type Point struct {
+    X int
+    Y int
+}
+
+// Add returns the point moved by given vector.
+func (p Point) Add(vector Point) Point {
+    return Point{X: p.X + vector.X, Y: p.Y + vector.Y}
+}
+
+// Scale returns the vector multiplied by given factor.
+func (p Point) Scale(factor int) Point {
+    return Point{X: p.X * factor, Y: p.Y * factor}
+}
+
+var (
+    // Orthogonal lists unit vectors of the 4 basic directions (up, right, down, left).
+    Orthogonal = []Point{{0, -1}, {1, 0}, {0, 1}, {-1, 0}}
+    // AllDirections lists unit vectors of all 8 directions, the basic ones and their 45-degree rotations.
+    AllDirections = []Point{{0, -1}, {1, -1}, {1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}}
+)
+
+// Mode decides how the grid treats positions outside of its bounds.
+type Mode int
+
+const (
+    // Bounded grid has nothing outside of its bounds.
+    Bounded Mode = iota
+    // Wrapped grid repeats infinitely in all directions, so every position maps to some cell.
+    Wrapped
+)
+
+// Grid is a dense rectangular grid of cells of type T.
+type Grid[T any] struct {
+    width  int
+    height int
+    mode   Mode
+    cells  []T
+}
+
+// New creates a grid of given size with all cells set to zero value of T.
+func New[T any](width, height int, mode Mode) *Grid[T] {
+    if width < 0 || height < 0 {
+        panic(fmt.Sprintf("grid: invalid size %dx%d", width, height))
+    }
+
+    return &Grid[T]{
+        width:  width,
+        height: height,
+        mode:   mode,
+        cells:  make([]T, width*height),
+    }
+}
+
+func (g *Grid[T]) Width() int {
+    return g.width
+}
+
+func (g *Grid[T]) Height() int {
+    return g.height
+}
+
+func (g *Grid[T]) Mode() Mode {
+    return g.mode
+}
+
+// InBounds checks whether the point lies within the grid itself (regardless of grid mode).
+func (g *Grid[T]) InBounds(p Point) bool {
+    return p.X >= 0 && p.X < g.width && p.Y >= 0 && p.Y < g.height
+}
+
+// Resolve maps the point to the position of the cell it refers to. Wrapped grids map every point (unless they are
+// empty), bounded grids only those within bounds. Flag reports whether the point refers to any cell.
+func (g *Grid[T]) Resolve(p Point) (Point, bool) {
+    if g.mode == Wrapped && g.width > 0 && g.height > 0 {
+        return Point{X: wrap(p.X, g.width), Y: wrap(p.Y, g.height)}, true
+    }
+    return p, g.InBounds(p)
+}
+
+func wrap(value, size int) int {
+    value %= size
+    if value < 0 {
+        value += size
+    }
+    return value
+}
+
+// Get returns the cell on given position together with flag whether the position refers to any cell. Zero value is
+// returned for positions outside of bounded grid.
+func (g *Grid[T]) Get(p Point) (T, bool) {
+    resolved, ok := g.Resolve(p)
+    if !ok {
+        var zero T
+        return zero, false
+    }
+    return g.cells[resolved.Y*g.width+resolved.X], true
+}
+
+// Set changes the cell on given position and reports whether the position refers to any cell.
+func (g *Grid[T]) Set(p Point, value T) bool {
+    resolved, ok := g.Resolve(p)
+    if ok {
+        g.cells[resolved.Y*g.width+resolved.X] = value
+    }
+    return ok
+}
+
+// Each calls given function for every cell of the grid, row by row.
+func (g *Grid[T]) Each(f func(p Point, value T)) {
+    for i, value := range g.cells {
+        f(Point{X: i % g.width, Y: i / g.width}, value)
+    }
+}
+
+// EachNeighbour calls given function for every cell neighbouring the point in given directions (usually Orthogonal
+// or AllDirections). Positions not referring to any cell are skipped.
+func (g *Grid[T]) EachNeighbour(p Point, directions []Point, f func(p Point, value T)) {
+    for _, direction := range directions {
+        neighbour := p.Add(direction)
+        if value, ok := g.Get(neighbour); ok {
+            f(neighbour, value)
+        }
+    }
+}
+
+// Cast walks from the point (excluding it) along given vector and returns the first cell for which the hit function
+// returns true. Flag reports whether any cell was hit before leaving the bounded grid, or before the ray started
+// repeating itself in wrapped grid.
+func (g *Grid[T]) Cast(from Point, vector Point, hit func(value T) bool) (Point, T, bool) {
+    var zero T
+    if vector == (Point{}) {
+        return Point{}, zero, false
+    }
+
+    // Ray in wrapped grid has to come back to its origin in at most width*height steps.
+    for step, p := 0, from.Add(vector); step < len(g.cells); step, p = step+1, p.Add(vector) {
+        value, ok := g.Get(p)
+        if !ok {
+            break
+        }
+        if hit(value) {
+            return p, value, true
+        }
+    }
+
+    return Point{}, zero, false
+}
+
+// Count returns the number of cells matching given condition.
+func (g *Grid[T]) Count(match func(value T) bool) int {
+    count := 0
+    for _, value := range g.cells {
+        if match(value) {
+            count++
+        }
+    }
+    return count
+}
+
+// Clone creates an independent copy of the grid.
+func (g *Grid[T]) Clone() *Grid[T] {
+    clone := *g
+    clone.cells = append([]T(nil), g.cells...)
+    return &clone
+}
+
+// Decode loads the grid from text where every line is a row and every character a single cell. Characters are
+// translated to cells using given decode function. All rows have to be of the same width, empty lines are ignored.
+// Row of different width or with a character the decode function rejects is reported to the error handler, the row is
+// skipped unless the handler stops the decoding.
+func Decode[T any](reader io.Reader, mode Mode, decode func(char rune) (T, error),
+    onError input.ErrorHandler) (*Grid[T], error) {
+    g := &Grid[T]{mode: mode}
+
+    err := input.EachLine(reader, func(line input.Line) error {
+        row := []rune(line.Text)
+        if len(row) == 0 {
+            return nil
+        }
+
+        if g.height > 0 && len(row) != g.width {
+            return onError(input.Errorf(line, 0, "row has %d cells, expected %d", len(row), g.width))
+        }
+        cells := make([]T, len(row))
+        for column, char := range row {
+            value, err := decode(char)
+            if err != nil {
+                return onError(input.Errorf(line, column+1, "%w", err))
+            }
+            cells[column] = value
+        }
+
+        g.width = len(row)
+        g.cells = append(g.cells, cells...)
+        g.height++
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    return g, nil
+}
+
+// Encode writes the grid as text, one row per line, with cells translated to characters by given encode function.
+func (g *Grid[T]) Encode(writer io.Writer, encode func(value T) rune) error {
+    w := bufio.NewWriter(writer)
+    for i, value := range g.cells {
+        w.WriteRune(encode(value))
+        if (i+1)%g.width == 0 {
+            w.WriteByte('\n')
+        }
+    }
+    return w.Flush()
+}
+
+// Format returns the grid encoded as text (see Encode).
+func (g *Grid[T]) Format(encode func(value T) rune) string {
+    var sb strings.Builder
+    _ = g.Encode(&sb, encode)
+    return sb.String()
+}
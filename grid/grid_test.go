@@ -0,0 +1,137 @@
+package grid
+
+import (
+    "errors"
+    "strings"
+    "testing"
+
+    "AdventOfCode20/input"
+)
+
+func decodeRunes(t *testing.T, text string, mode Mode) *Grid[rune] {
+    g, err := Decode(strings.NewReader(text), mode, func(char rune) (rune, error) { return char, nil },
+        input.FailOnError)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return g
+}
+
+func TestDecodeEncodeRoundTrip(t *testing.T) {
+    text := "#..\n.#.\n..#\n.##\n"
+    g := decodeRunes(t, text, Bounded)
+
+    if g.Width() != 3 || g.Height() != 4 {
+        t.Fatalf("got size %dx%d, want 3x4", g.Width(), g.Height())
+    }
+    if encoded := g.Format(func(r rune) rune { return r }); encoded != text {
+        t.Errorf("got %q, want %q", encoded, text)
+    }
+}
+
+func TestDecodeErrors(t *testing.T) {
+    _, err := Decode(strings.NewReader("...\n..\n"), Bounded, func(char rune) (rune, error) { return char, nil },
+        input.FailOnError)
+    if err == nil || !strings.Contains(err.Error(), "line 2") {
+        t.Errorf("expected error of ragged line 2, got %v", err)
+    }
+
+    invalid := errors.New("invalid cell")
+    decode := func(char rune) (rune, error) {
+        if char == 'x' {
+            return 0, invalid
+        }
+        return char, nil
+    }
+    _, err = Decode(strings.NewReader("..\n.x\n"), Bounded, decode, input.FailOnError)
+    if !errors.Is(err, invalid) || !strings.Contains(err.Error(), "line 2, column 2") {
+        t.Errorf("expected positioned decode error, got %v", err)
+    }
+
+    // Lenient handler skips the invalid rows.
+    var skipped []string
+    g, err := Decode(strings.NewReader("..\n.x\n...\n#.\n"), Bounded, decode, func(err error) error {
+        skipped = append(skipped, err.Error())
+        return nil
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+    if encoded := g.Format(func(r rune) rune { return r }); encoded != "..\n#.\n" || len(skipped) != 2 {
+        t.Errorf("got grid %q after skipping %v", encoded, skipped)
+    }
+}
+
+func TestBoundedAndWrappedAccess(t *testing.T) {
+    bounded := decodeRunes(t, "ab\ncd\n", Bounded)
+    wrapped := decodeRunes(t, "ab\ncd\n", Wrapped)
+
+    for _, tc := range []struct {
+        p           Point
+        wrapped     rune
+        inBoundedOk bool
+    }{
+        {Point{0, 0}, 'a', true},
+        {Point{1, 1}, 'd', true},
+        {Point{2, 0}, 'a', false},
+        {Point{-1, 0}, 'b', false},
+        {Point{3, -3}, 'd', false},
+    } {
+        if _, ok := bounded.Get(tc.p); ok != tc.inBoundedOk {
+            t.Errorf("bounded %v: got ok=%v, want %v", tc.p, ok, tc.inBoundedOk)
+        }
+        if value, ok := wrapped.Get(tc.p); !ok || value != tc.wrapped {
+            t.Errorf("wrapped %v: got %q (ok=%v), want %q", tc.p, value, ok, tc.wrapped)
+        }
+    }
+
+    if bounded.Set(Point{5, 5}, 'x') {
+        t.Error("bounded grid accepted value outside of its bounds")
+    }
+}
+
+func TestNeighboursAndCast(t *testing.T) {
+    g := decodeRunes(t, "#.#\n...\n..#\n", Bounded)
+
+    count := func(directions []Point) int {
+        n := 0
+        g.EachNeighbour(Point{1, 1}, directions, func(_ Point, value rune) {
+            if value == '#' {
+                n++
+            }
+        })
+        return n
+    }
+    if n := count(Orthogonal); n != 0 {
+        t.Errorf("got %d orthogonal neighbours, want 0", n)
+    }
+    if n := count(AllDirections); n != 3 {
+        t.Errorf("got %d neighbours, want 3", n)
+    }
+
+    isTree := func(value rune) bool { return value == '#' }
+    if p, _, ok := g.Cast(Point{0, 0}, Point{1, 1}, isTree); !ok || p != (Point{2, 2}) {
+        t.Errorf("diagonal cast: got %v (ok=%v), want {2 2}", p, ok)
+    }
+    if _, _, ok := g.Cast(Point{0, 0}, Point{0, 1}, isTree); ok {
+        t.Error("downward cast should leave the grid without hit")
+    }
+
+    wrapped := decodeRunes(t, "#..\n...\n", Wrapped)
+    if p, _, ok := wrapped.Cast(Point{1, 0}, Point{1, 0}, isTree); !ok || p != (Point{3, 0}) {
+        t.Errorf("wrapped cast: got %v (ok=%v), want {3 0}", p, ok)
+    }
+    if _, _, ok := wrapped.Cast(Point{0, 1}, Point{1, 0}, isTree); ok {
+        t.Error("wrapped cast without any hit has to terminate")
+    }
+}
+
+func TestCloneIsIndependent(t *testing.T) {
+    g := decodeRunes(t, "ab\n", Bounded)
+    clone := g.Clone()
+    clone.Set(Point{0, 0}, 'x')
+
+    if value, _ := g.Get(Point{0, 0}); value != 'a' {
+        t.Errorf("original changed to %q", value)
+    }
+}
@@ -0,0 +1,103 @@
+package input
+
+import (
+    "errors"
+    "fmt"
+    "io"
+)
+
+// Diagnostic describes a problem found in the input file. Line and column are numbered from 1, zero means unknown.
+type Diagnostic struct {
+    File    string
+    Line    int
+    Column  int
+    Text    string
+    Message string
+}
+
+func (d Diagnostic) Error() string {
+    switch {
+    case d.Line > 0 && d.Column > 0:
+        return fmt.Sprintf("%s:%d:%d: %s", d.File, d.Line, d.Column, d.Message)
+    case d.Line > 0:
+        return fmt.Sprintf("%s:%d: %s", d.File, d.Line, d.Message)
+    default:
+        return fmt.Sprintf("%s: %s", d.File, d.Message)
+    }
+}
+
+// Mode decides whether the problems of the input stop the parsing.
+type Mode int
+
+const (
+    // Lenient mode skips invalid parts of the input, they are only collected to be summarized later.
+    Lenient Mode = iota
+    // Strict mode stops the parsing on the first invalid part of the input.
+    Strict
+)
+
+// Diagnostics collects problems found in a single input file. Its Handle method is meant to be passed to parsers as
+// their ErrorHandler.
+type Diagnostics struct {
+    file        string
+    mode        Mode
+    diagnostics []Diagnostic
+}
+
+// NewDiagnostics creates empty collection of problems of given file.
+func NewDiagnostics(file string, mode Mode) *Diagnostics {
+    return &Diagnostics{file: file, mode: mode}
+}
+
+// Handle records the problem of the input. Lenient mode then lets the parser skip the invalid part, strict mode
+// returns the diagnostic as an error to stop the parsing.
+func (d *Diagnostics) Handle(err error) error {
+    diagnostic := Diagnostic{File: d.file, Message: err.Error()}
+
+    var inputErr *Error
+    if errors.As(err, &inputErr) {
+        diagnostic.Line = inputErr.Line
+        diagnostic.Column = inputErr.Column
+        diagnostic.Text = inputErr.Text
+        diagnostic.Message = inputErr.Err.Error()
+    }
+
+    d.diagnostics = append(d.diagnostics, diagnostic)
+    if d.mode == Strict {
+        return diagnostic
+    }
+    return nil
+}
+
+// Diagnostics returns all problems recorded so far, in order of their appearance.
+func (d *Diagnostics) Diagnostics() []Diagnostic {
+    return d.diagnostics
+}
+
+// Ignore skips the invalid parts of the input without any record. It is only meant for repeated parsing of the input
+// whose problems are reported elsewhere (e.g. in benchmarks).
+func Ignore(err error) error {
+    return nil
+}
+
+// WriteSummary writes the overview of all given problems (typically the skipped lines of lenient parsing).
+func WriteSummary(w io.Writer, diagnostics []Diagnostic) error {
+    if len(diagnostics) == 0 {
+        return nil
+    }
+
+    if _, err := fmt.Fprintf(w, "%d invalid part(s) of the input skipped:\n", len(diagnostics)); err != nil {
+        return err
+    }
+    for _, diagnostic := range diagnostics {
+        if _, err := fmt.Fprintf(w, "    %v\n", diagnostic); err != nil {
+            return err
+        }
+        if diagnostic.Text != "" {
+            if _, err := fmt.Fprintf(w, "        %q\n", diagnostic.Text); err != nil {
+                return err
+            }
+        }
+    }
+    return nil
+}
@@ -0,0 +1,125 @@
+// Package input contains helpers shared by puzzle input loaders: streaming line iteration, grouping of lines into
+// blank-line separated records, integer lists and regex-driven extraction of line data into structures.
+package input
+
+import (
+    "bufio"
+    "fmt"
+    "io"
+    "strconv"
+    "strings"
+)
+
+// Longest line the readers accept. Puzzle inputs have short lines, but some of them (e.g. a grid) may get long.
+const maxLineLength = 1024 * 1024
+
+// Line is a single line of the input together with its number (lines are numbered from 1).
+type Line struct {
+    Number int
+    Text   string
+}
+
+// Error describes invalid part of the input. Column is numbered from 1, zero means the whole line is invalid.
+type Error struct {
+    Line   int
+    Column int
+    Text   string
+    Err    error
+}
+
+func (e *Error) Error() string {
+    if e.Column > 0 {
+        return fmt.Sprintf("line %d, column %d: %v", e.Line, e.Column, e.Err)
+    }
+    return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+    return e.Err
+}
+
+// Errorf creates an error related to given line (and its column, if known).
+func Errorf(line Line, column int, format string, args ...interface{}) *Error {
+    return &Error{Line: line.Number, Column: column, Text: line.Text, Err: fmt.Errorf(format, args...)}
+}
+
+// ErrorHandler decides what happens with the invalid part of the input (usually described by *Error). Returning nil
+// skips the invalid part, returning an error stops the parsing with it.
+type ErrorHandler func(err error) error
+
+// FailOnError stops the parsing on the first invalid part of the input.
+func FailOnError(err error) error {
+    return err
+}
+
+// EachLine calls given function for every line of the input. Lines are streamed, so the input is never loaded into
+// memory as a whole. Windows line endings are removed. Iteration stops on the first error returned by the function.
+func EachLine(reader io.Reader, f func(line Line) error) error {
+    scanner := bufio.NewScanner(reader)
+    scanner.Buffer(make([]byte, 0, 64*1024), maxLineLength)
+
+    number := 0
+    for scanner.Scan() {
+        number++
+        if err := f(Line{Number: number, Text: strings.TrimSuffix(scanner.Text(), "\r")}); err != nil {
+            return err
+        }
+    }
+    return scanner.Err()
+}
+
+// EachParagraph groups lines into records which are separated by wholly empty lines (record can span over multiple
+// lines) and calls given function for every record. Multiple empty lines never produce an empty record.
+func EachParagraph(reader io.Reader, f func(lines []Line) error) error {
+    var paragraph []Line
+    err := EachLine(reader, func(line Line) error {
+        if line.Text != "" {
+            paragraph = append(paragraph, line)
+            return nil
+        }
+        if len(paragraph) == 0 {
+            return nil
+        }
+
+        err := f(paragraph)
+        paragraph = nil
+        return err
+    })
+    if err != nil {
+        return err
+    }
+
+    if len(paragraph) > 0 {
+        return f(paragraph)
+    }
+    return nil
+}
+
+// ParseInt converts the whole line into an integer.
+func ParseInt(line Line) (int, error) {
+    value, err := strconv.Atoi(strings.TrimSpace(line.Text))
+    if err != nil {
+        return 0, Errorf(line, 0, "%q is not a number", line.Text)
+    }
+    return value, nil
+}
+
+// Ints loads every line of the input as an integer. Non-numeric lines are passed to the error handler, empty lines
+// are ignored.
+func Ints(reader io.Reader, onError ErrorHandler) ([]int, error) {
+    var numbers []int
+    err := EachLine(reader, func(line Line) error {
+        if strings.TrimSpace(line.Text) == "" {
+            return nil
+        }
+
+        number, err := ParseInt(line)
+        if err != nil {
+            return onError(err)
+        }
+        numbers = append(numbers, number)
+        return nil
+    })
+
+    return numbers, err
+}
@@ -0,0 +1,129 @@
+package input
+
+import (
+    "errors"
+    "reflect"
+    "strings"
+    "testing"
+)
+
+func TestEachLineStripsWindowsLineEndings(t *testing.T) {
+    var lines []Line
+    err := EachLine(strings.NewReader("a\r\nb\r\n\r\nc"), func(line Line) error {
+        lines = append(lines, line)
+        return nil
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    expected := []Line{{1, "a"}, {2, "b"}, {3, ""}, {4, "c"}}
+    if !reflect.DeepEqual(lines, expected) {
+        t.Errorf("got %v, want %v", lines, expected)
+    }
+}
+
+func TestEachParagraph(t *testing.T) {
+    var paragraphs [][]string
+    err := EachParagraph(strings.NewReader("\na\nb\n\n\nc\n\n"), func(lines []Line) error {
+        var texts []string
+        for _, line := range lines {
+            texts = append(texts, line.Text)
+        }
+        paragraphs = append(paragraphs, texts)
+        return nil
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    expected := [][]string{{"a", "b"}, {"c"}}
+    if !reflect.DeepEqual(paragraphs, expected) {
+        t.Errorf("got %v, want %v", paragraphs, expected)
+    }
+}
+
+func TestInts(t *testing.T) {
+    numbers, err := Ints(strings.NewReader("1\n-2\n\n3\n"), FailOnError)
+    if err != nil || !reflect.DeepEqual(numbers, []int{1, -2, 3}) {
+        t.Errorf("got %v (%v), want [1 -2 3]", numbers, err)
+    }
+
+    _, err = Ints(strings.NewReader("1\nx\n3\n"), FailOnError)
+    var inputErr *Error
+    if !errors.As(err, &inputErr) || inputErr.Line != 2 || inputErr.Text != "x" {
+        t.Errorf("expected error of line 2, got %v", err)
+    }
+
+    var skipped []error
+    numbers, err = Ints(strings.NewReader("1\nx\n3\n"), func(err error) error {
+        skipped = append(skipped, err)
+        return nil
+    })
+    if err != nil || len(skipped) != 1 || !reflect.DeepEqual(numbers, []int{1, 3}) {
+        t.Errorf("got %v (%v) with %d skipped lines, want [1 3] with 1 skipped", numbers, err, len(skipped))
+    }
+}
+
+func TestPatternExtract(t *testing.T) {
+    pattern := MustCompile(`^(?P<min>\d+)-(?P<max>\d+) (?P<letter>[a-z]): (?P<word>\w+)$`)
+    var record struct {
+        Min    int
+        Max    int
+        Letter uint8
+        Text   string `input:"word"`
+    }
+
+    if err := pattern.Extract(Line{1, "1-3 a: abcde"}, &record); err != nil {
+        t.Fatal(err)
+    }
+    if record.Min != 1 || record.Max != 3 || record.Letter != 'a' || record.Text != "abcde" {
+        t.Errorf("unexpected extracted record %+v", record)
+    }
+
+    err := pattern.Extract(Line{7, "1-99999999999999999999 a: abc"}, &record)
+    var inputErr *Error
+    if !errors.As(err, &inputErr) || inputErr.Line != 7 || inputErr.Column != 3 {
+        t.Errorf("expected error of line 7, column 3, got %v", err)
+    }
+
+    if err := pattern.Extract(Line{8, "nonsense"}, &record); err == nil {
+        t.Error("expected error of non-matching line")
+    }
+}
+
+func TestPatternExtractAll(t *testing.T) {
+    pattern := MustCompile(`(?P<count>\d) (?P<color>\w+)`)
+    var bags []struct {
+        Count int
+        Color string
+    }
+
+    if err := pattern.ExtractAll(Line{1, "contains 1 red, 2 blue"}, &bags); err != nil {
+        t.Fatal(err)
+    }
+    if len(bags) != 2 || bags[0].Count != 1 || bags[0].Color != "red" || bags[1].Count != 2 || bags[1].Color != "blue" {
+        t.Errorf("unexpected extracted bags %+v", bags)
+    }
+}
+
+func TestDiagnosticsModes(t *testing.T) {
+    lenient := NewDiagnostics("numbers.txt", Lenient)
+    numbers, err := Ints(strings.NewReader("1\nx\n3\ny\n"), lenient.Handle)
+    if err != nil || !reflect.DeepEqual(numbers, []int{1, 3}) {
+        t.Errorf("lenient: got %v (%v), want [1 3]", numbers, err)
+    }
+    if diagnostics := lenient.Diagnostics(); len(diagnostics) != 2 || diagnostics[1].Line != 4 {
+        t.Errorf("lenient: unexpected diagnostics %v", diagnostics)
+    }
+
+    strict := NewDiagnostics("numbers.txt", Strict)
+    _, err = Ints(strings.NewReader("1\nx\n3\ny\n"), strict.Handle)
+    var diagnostic Diagnostic
+    if !errors.As(err, &diagnostic) || diagnostic.File != "numbers.txt" || diagnostic.Line != 2 {
+        t.Errorf("strict: expected diagnostic of numbers.txt:2, got %v", err)
+    }
+    if err.Error() != `numbers.txt:2: "x" is not a number` {
+        t.Errorf("strict: unexpected message %q", err.Error())
+    }
+}
@@ -0,0 +1,116 @@
+package input
+
+import (
+    "errors"
+    "fmt"
+    "reflect"
+    "regexp"
+    "strconv"
+    "strings"
+)
+
+// Pattern extracts data of a line into a structure using regular expression with named groups. Every named group is
+// stored into the structure field of the same name (compared case-insensitively) or into the field tagged with
+// `input:"name"`. Supported field types are strings, integers and bytes (single character).
+type Pattern struct {
+    regexp *regexp.Regexp
+}
+
+// MustCompile creates pattern from regular expression and panics if the expression is invalid.
+func MustCompile(expr string) *Pattern {
+    return &Pattern{regexp: regexp.MustCompile(expr)}
+}
+
+// Regexp returns the underlying regular expression.
+func (p *Pattern) Regexp() *regexp.Regexp {
+    return p.regexp
+}
+
+// Extract fills the structure (given as a pointer) with the first match of the pattern within the line. Error
+// describes whether the line does not match at all or which group could not be converted to its field.
+func (p *Pattern) Extract(line Line, target interface{}) error {
+    match := p.regexp.FindStringSubmatchIndex(line.Text)
+    if match == nil {
+        return Errorf(line, 0, "line does not match format %s", p.regexp)
+    }
+
+    return p.fill(line, match, reflect.ValueOf(target))
+}
+
+// ExtractAll appends a new element to the slice (given as a pointer to slice of structures) for every match of the
+// pattern within the line.
+func (p *Pattern) ExtractAll(line Line, target interface{}) error {
+    slice := reflect.ValueOf(target)
+    if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+        panic(fmt.Sprintf("input: ExtractAll target has to be pointer to slice, got %T", target))
+    }
+    slice = slice.Elem()
+
+    for _, match := range p.regexp.FindAllStringSubmatchIndex(line.Text, -1) {
+        element := reflect.New(slice.Type().Elem())
+        if err := p.fill(line, match, element); err != nil {
+            return err
+        }
+        slice.Set(reflect.Append(slice, element.Elem()))
+    }
+    return nil
+}
+
+func (p *Pattern) fill(line Line, match []int, target reflect.Value) error {
+    if target.Kind() != reflect.Ptr || target.Elem().Kind() != reflect.Struct {
+        panic(fmt.Sprintf("input: pattern target has to be pointer to structure, got %s", target.Type()))
+    }
+    structure := target.Elem()
+
+    for group, name := range p.regexp.SubexpNames() {
+        start, end := match[2*group], match[2*group+1]
+        if name == "" || start < 0 {
+            continue
+        }
+
+        field := findField(structure, name)
+        if !field.IsValid() {
+            continue
+        }
+        if err := setField(field, line.Text[start:end]); err != nil {
+            return Errorf(line, start+1, "invalid %s %q: %w", name, line.Text[start:end], err)
+        }
+    }
+    return nil
+}
+
+// Looks up the settable field for given group name, either by its tag or by its name.
+func findField(structure reflect.Value, name string) reflect.Value {
+    structType := structure.Type()
+    for i := 0; i < structType.NumField(); i++ {
+        field := structType.Field(i)
+        tag, hasTag := field.Tag.Lookup("input")
+        if (hasTag && tag == name) || (!hasTag && strings.EqualFold(field.Name, name)) {
+            if value := structure.Field(i); value.CanSet() {
+                return value
+            }
+        }
+    }
+    return reflect.Value{}
+}
+
+func setField(field reflect.Value, value string) error {
+    switch field.Kind() {
+    case reflect.String:
+        field.SetString(value)
+    case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+        number, err := strconv.ParseInt(value, 10, field.Type().Bits())
+        if err != nil {
+            return errors.New("not a number")
+        }
+        field.SetInt(number)
+    case reflect.Uint8:
+        if len(value) != 1 {
+            return errors.New("not a single character")
+        }
+        field.SetUint(uint64(value[0]))
+    default:
+        panic(fmt.Sprintf("input: unsupported field type %s", field.Type()))
+    }
+    return nil
+}
@@ -0,0 +1,75 @@
+// Package cli contains helpers shared by the command line tools: flag sets of subcommands which accept flags anywhere
+// among their arguments, and the "-" path standing for standard input or output.
+package cli
+
+import (
+    "flag"
+    "fmt"
+    "io"
+    "os"
+)
+
+// Stdio is the path standing for standard input (of input files) or standard output (of output files).
+const Stdio = "-"
+
+// Tool describes the command line tool for usage messages of its subcommands.
+type Tool struct {
+    // Name of the executable.
+    Name string
+    // Operands describes positional arguments of the subcommands, e.g. "<program>".
+    Operands string
+}
+
+// NewFlagSet creates the flag set of the subcommand. Invalid flags print the usage and exit the tool.
+func (t Tool) NewFlagSet(command string) *flag.FlagSet {
+    flags := flag.NewFlagSet(command, flag.ExitOnError)
+    flags.Usage = func() {
+        fmt.Fprintf(os.Stderr, "Usage: %s %s [flags] %s\n\nFlags:\n", t.Name, command, t.Operands)
+        flags.PrintDefaults()
+    }
+    return flags
+}
+
+// ParseInterspersed parses the flags and returns the positional arguments. Standard flag package stops parsing at the
+// first positional argument, this allows flags to be given both before and after the positional arguments (e.g.
+// "aoc run 7 -input data.txt").
+func ParseInterspersed(flags *flag.FlagSet, args []string) []string {
+    var positional []string
+    for {
+        // Flag set was created with ExitOnError, so parsing never returns an error here.
+        _ = flags.Parse(args)
+        if flags.NArg() == 0 {
+            return positional
+        }
+        positional = append(positional, flags.Arg(0))
+        args = flags.Args()[1:]
+    }
+}
+
+// SingleOperand parses the flags and returns the only positional argument. Usage is printed if there is not exactly
+// one, the flag only signals whether the command can continue.
+func SingleOperand(flags *flag.FlagSet, args []string) (string, bool) {
+    positional := ParseInterspersed(flags, args)
+    if len(positional) != 1 {
+        flags.Usage()
+        return "", false
+    }
+    return positional[0], true
+}
+
+// WriteOutput writes the output into given file, or to standard output for Stdio path.
+func WriteOutput(path string, write func(w io.Writer) error) error {
+    if path == Stdio {
+        return write(os.Stdout)
+    }
+
+    file, err := os.Create(path)
+    if err != nil {
+        return err
+    }
+    if err := write(file); err != nil {
+        file.Close()
+        return err
+    }
+    return file.Close()
+}
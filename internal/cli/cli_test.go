@@ -0,0 +1,24 @@
+package cli
+
+import (
+    "fmt"
+    "testing"
+)
+
+func TestParseInterspersed(t *testing.T) {
+    tool := Tool{Name: "test", Operands: "<file>"}
+    flags := tool.NewFlagSet("run")
+    n := flags.Int("n", 0, "number")
+    verbose := flags.Bool("v", false, "verbose")
+
+    positional := ParseInterspersed(flags, []string{"a", "-n", "3", "b", "-v", "c"})
+    if fmt.Sprint(positional) != "[a b c]" || *n != 3 || !*verbose {
+        t.Errorf("got positional %v, -n %d, -v %v", positional, *n, *verbose)
+    }
+
+    // Everything after "--" is positional.
+    positional = ParseInterspersed(tool.NewFlagSet("run"), []string{"--", "-n"})
+    if fmt.Sprint(positional) != "[-n]" {
+        t.Errorf("got positional %v, want [-n]", positional)
+    }
+}
@@ -0,0 +1,207 @@
+package password
+
+import (
+    "bufio"
+    "encoding/json"
+    "fmt"
+    "io"
+    "sort"
+    "strings"
+)
+
+// Audit summarizes the password database: how its records do against the policies, which letters they use and which
+// passwords repeat.
+type Audit struct {
+    Records  int           `json:"records"`
+    Policies []PolicyAudit `json:"policies"`
+    // PasswordLetters counts all letters of all passwords.
+    PasswordLetters []LetterCount `json:"password_letters"`
+    // RuleLetters counts the letters of record rules.
+    RuleLetters []LetterCount `json:"rule_letters"`
+    Duplicates  []Duplicate   `json:"duplicates"`
+}
+
+// PolicyAudit is the result of a single policy over the whole database.
+type PolicyAudit struct {
+    Name    string        `json:"name"`
+    Passed  int           `json:"passed"`
+    Failed  int           `json:"failed"`
+    Reasons []ReasonCount `json:"reasons"`
+}
+
+// ReasonCount is the number of records failing the policy for the reason.
+type ReasonCount struct {
+    Reason Reason `json:"reason"`
+    Count  int    `json:"count"`
+}
+
+// LetterCount is a single bar of the letter frequency histogram.
+type LetterCount struct {
+    Letter string `json:"letter"`
+    Count  int    `json:"count"`
+}
+
+// Duplicate is the password used by more records (in NFC).
+type Duplicate struct {
+    Password string `json:"password"`
+    Lines    []int  `json:"lines"`
+}
+
+// NewAudit checks all records against the policies and collects the statistics of the database.
+func NewAudit(records []Record, policies []Policy) *Audit {
+    audit := &Audit{Records: len(records)}
+
+    for _, policy := range policies {
+        policyAudit := PolicyAudit{Name: policy.Name()}
+        reasons := make(map[Reason]int)
+        for _, record := range records {
+            if violation := policy.Check(record); violation != nil {
+                policyAudit.Failed++
+                reasons[violation.Reason]++
+            } else {
+                policyAudit.Passed++
+            }
+        }
+        for reason, count := range reasons {
+            policyAudit.Reasons = append(policyAudit.Reasons, ReasonCount{Reason: reason, Count: count})
+        }
+        sort.Slice(policyAudit.Reasons, func(i, j int) bool {
+            a, b := policyAudit.Reasons[i], policyAudit.Reasons[j]
+            return a.Count > b.Count || a.Count == b.Count && a.Reason < b.Reason
+        })
+        audit.Policies = append(audit.Policies, policyAudit)
+    }
+
+    // Letters are counted as whole characters in NFC, so a letter is counted once however it is written, and so are
+    // the passwords differing only in the way their letters are written.
+    passwordLetters := make(map[string]int)
+    ruleLetters := make(map[string]int)
+    lines := make(map[string][]int)
+    for _, record := range records {
+        password := Normalize(record.Password, NFC)
+        for _, letter := range Graphemes(password) {
+            passwordLetters[letter]++
+        }
+        ruleLetters[Normalize(record.Rule.Letter, NFC)]++
+        lines[password] = append(lines[password], record.Line)
+    }
+    audit.PasswordLetters = histogram(passwordLetters)
+    audit.RuleLetters = histogram(ruleLetters)
+
+    for password, passwordLines := range lines {
+        if len(passwordLines) > 1 {
+            audit.Duplicates = append(audit.Duplicates, Duplicate{Password: password, Lines: passwordLines})
+        }
+    }
+    sort.Slice(audit.Duplicates, func(i, j int) bool {
+        return audit.Duplicates[i].Lines[0] < audit.Duplicates[j].Lines[0]
+    })
+    return audit
+}
+
+// Sorts the counts of letters alphabetically.
+func histogram(counts map[string]int) []LetterCount {
+    var result []LetterCount
+    for letter, count := range counts {
+        result = append(result, LetterCount{Letter: letter, Count: count})
+    }
+    sort.Slice(result, func(i, j int) bool {
+        return result[i].Letter < result[j].Letter
+    })
+    return result
+}
+
+// WriteJSON exports the audit as indented JSON document.
+func (a *Audit) WriteJSON(w io.Writer) error {
+    encoder := json.NewEncoder(w)
+    encoder.SetIndent("", "    ")
+    return encoder.Encode(a)
+}
+
+// Width of the longest bar of histograms in Markdown report.
+const histogramWidth = 40
+
+// WriteMarkdown exports the audit as Markdown document with tables of policies and histograms of letters.
+func (a *Audit) WriteMarkdown(w io.Writer) error {
+    bw := bufio.NewWriter(w)
+    fmt.Fprintf(bw, "# Password database audit\n\n%d record(s) audited.\n\n", a.Records)
+
+    fmt.Fprintln(bw, "## Policies")
+    fmt.Fprintln(bw)
+    fmt.Fprintln(bw, "| Policy | Passed | Failed | Failure reasons |")
+    fmt.Fprintln(bw, "|---|---:|---:|---|")
+    for _, policy := range a.Policies {
+        var reasons []string
+        for _, reason := range policy.Reasons {
+            reasons = append(reasons, fmt.Sprintf("%s: %d", reason.Reason, reason.Count))
+        }
+        fmt.Fprintf(bw, "| %s | %d | %d | %s |\n", escapeMarkdown(policy.Name), policy.Passed, policy.Failed,
+            strings.Join(reasons, ", "))
+    }
+
+    writeHistogram(bw, "Letters of passwords", a.PasswordLetters)
+    writeHistogram(bw, "Letters of rules", a.RuleLetters)
+
+    fmt.Fprintln(bw)
+    fmt.Fprintln(bw, "## Duplicate passwords")
+    fmt.Fprintln(bw)
+    if len(a.Duplicates) == 0 {
+        fmt.Fprintln(bw, "No password is used twice.")
+    } else {
+        fmt.Fprintln(bw, "| Password | Lines |")
+        fmt.Fprintln(bw, "|---|---|")
+        for _, duplicate := range a.Duplicates {
+            lines := strings.Trim(fmt.Sprint(duplicate.Lines), "[]")
+            fmt.Fprintf(bw, "| %s | %s |\n", codeSpan(duplicate.Password), lines)
+        }
+    }
+    return bw.Flush()
+}
+
+func writeHistogram(bw *bufio.Writer, title string, counts []LetterCount) {
+    fmt.Fprintf(bw, "\n## %s\n\n", title)
+    if len(counts) == 0 {
+        fmt.Fprintln(bw, "No letters.")
+        return
+    }
+
+    highest := 0
+    for _, count := range counts {
+        if count.Count > highest {
+            highest = count.Count
+        }
+    }
+    fmt.Fprintln(bw, "```")
+    for _, count := range counts {
+        bar := (count.Count*histogramWidth + highest - 1) / highest
+        fmt.Fprintf(bw, "%s %-*s %d\n", count.Letter, histogramWidth, strings.Repeat("#", bar), count.Count)
+    }
+    fmt.Fprintln(bw, "```")
+}
+
+// Escapes characters with special meaning in Markdown tables.
+func escapeMarkdown(text string) string {
+    return strings.NewReplacer("|", `\|`, "`", "\\`").Replace(text)
+}
+
+// Writes the text as code span of Markdown table cell. Fence is one backtick longer than the longest run of backticks
+// in the text, and it is padded by spaces if the text starts or ends with backtick. Pipes have to be escaped even in
+// code spans, otherwise they split the cell.
+func codeSpan(text string) string {
+    longest, run := 0, 0
+    for _, r := range text {
+        if r == '`' {
+            run++
+            if run > longest {
+                longest = run
+            }
+        } else {
+            run = 0
+        }
+    }
+    fence := strings.Repeat("`", longest+1)
+    if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+        text = " " + text + " "
+    }
+    return fence + strings.ReplaceAll(text, "|", `\|`) + fence
+}
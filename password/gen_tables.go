@@ -0,0 +1,165 @@
+//go:build ignore
+
+// Generates normalize_tables.go from UnicodeData.txt of the Unicode character database
+// (https://www.unicode.org/Public/14.0.0/ucd/UnicodeData.txt), which is not stored in the repository:
+//
+//     go run gen_tables.go -ucd UnicodeData.txt
+//
+// Only the blocks of Latin, Greek and Cyrillic letters, the combining marks and the letterlike symbols are included,
+// the normalization of passwords does not need more.
+package main
+
+import (
+    "bufio"
+    "bytes"
+    "flag"
+    "fmt"
+    "log"
+    "os"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+// Blocks of the characters whose canonical decompositions are included.
+var decompositionRanges = [][2]rune{
+    {0x00C0, 0x024F}, // Latin-1 Supplement letters, Latin Extended-A and B
+    {0x0340, 0x0344}, // combining marks decomposing to other marks
+    {0x0370, 0x03FF}, // Greek and Coptic
+    {0x0400, 0x052F}, // Cyrillic and Cyrillic Supplement
+    {0x1E00, 0x1EFF}, // Latin Extended Additional
+    {0x1F00, 0x1FFF}, // Greek Extended
+    {0x2100, 0x214F}, // Letterlike Symbols (Ohm, Kelvin and Angstrom signs)
+}
+
+// Blocks of the combining marks whose combining classes are included.
+var combiningRanges = [][2]rune{
+    {0x0300, 0x036F}, // Combining Diacritical Marks
+    {0x0483, 0x0487}, // Cyrillic combining marks
+}
+
+func inRanges(r rune, ranges [][2]rune) bool {
+    for _, bounds := range ranges {
+        if r >= bounds[0] && r <= bounds[1] {
+            return true
+        }
+    }
+    return false
+}
+
+func main() {
+    ucd := flag.String("ucd", "UnicodeData.txt", "path of UnicodeData.txt")
+    output := flag.String("output", "normalize_tables.go", "path of the generated file")
+    flag.Parse()
+
+    decompositions, classes, err := readUnicodeData(*ucd)
+    if err != nil {
+        log.Fatal(err)
+    }
+    if err := os.WriteFile(*output, writeTables(decompositions, classes), 0o644); err != nil {
+        log.Fatal(err)
+    }
+}
+
+// Reads code point (field 0), canonical combining class (field 3) and decomposition mapping (field 5) of the
+// characters. Compatibility decompositions (tagged, e.g. "<compat> 0020 0308") are skipped.
+func readUnicodeData(path string) (map[rune][]rune, map[rune]uint8, error) {
+    file, err := os.Open(path)
+    if err != nil {
+        return nil, nil, err
+    }
+    defer file.Close()
+
+    decompositions := make(map[rune][]rune)
+    classes := make(map[rune]uint8)
+    scanner := bufio.NewScanner(file)
+    for line := 1; scanner.Scan(); line++ {
+        fields := strings.Split(scanner.Text(), ";")
+        if len(fields) < 6 {
+            return nil, nil, fmt.Errorf("%s:%d: expected at least 6 fields", path, line)
+        }
+        code, err := strconv.ParseUint(fields[0], 16, 32)
+        if err != nil {
+            return nil, nil, fmt.Errorf("%s:%d: invalid code point %q", path, line, fields[0])
+        }
+        r := rune(code)
+
+        class, err := strconv.ParseUint(fields[3], 10, 8)
+        if err != nil {
+            return nil, nil, fmt.Errorf("%s:%d: invalid combining class %q", path, line, fields[3])
+        }
+        if class > 0 && inRanges(r, combiningRanges) {
+            classes[r] = uint8(class)
+        }
+
+        mapping := fields[5]
+        if mapping == "" || strings.HasPrefix(mapping, "<") || !inRanges(r, decompositionRanges) {
+            continue
+        }
+        for _, part := range strings.Fields(mapping) {
+            value, err := strconv.ParseUint(part, 16, 32)
+            if err != nil {
+                return nil, nil, fmt.Errorf("%s:%d: invalid decomposition %q", path, line, mapping)
+            }
+            decompositions[r] = append(decompositions[r], rune(value))
+        }
+    }
+    return decompositions, classes, scanner.Err()
+}
+
+func sortedKeys[V any](table map[rune]V) []rune {
+    keys := make([]rune, 0, len(table))
+    for r := range table {
+        keys = append(keys, r)
+    }
+    sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+    return keys
+}
+
+// Writes the source of the tables, a few entries per line.
+func writeTables(decompositions map[rune][]rune, classes map[rune]uint8) []byte {
+    var b bytes.Buffer
+    b.WriteString(`// Code generated by "go run gen_tables.go"; DO NOT EDIT.
+
+package password
+
+// Tables of the limited Unicode normalization, generated from Unicode character database. They cover the canonical
+// decompositions of Latin, Greek and Cyrillic letters, combining marks and letterlike symbols, and the combining
+// classes of the marks.
+
+// Canonical decompositions of characters into one character (singletons, e.g. Angstrom sign to letter Å) or two
+// characters (first of them may decompose further).
+var decompositions = map[rune][]rune{
+`)
+    writeEntries(&b, sortedKeys(decompositions), 3, func(r rune) string {
+        parts := make([]string, len(decompositions[r]))
+        for i, part := range decompositions[r] {
+            parts[i] = fmt.Sprintf("0x%04X", part)
+        }
+        return fmt.Sprintf("0x%04X: {%s},", r, strings.Join(parts, ", "))
+    })
+    b.WriteString(`}
+
+// Canonical combining classes of combining marks. Characters missing in the table have class 0 (starters).
+var combiningClasses = map[rune]uint8{
+`)
+    writeEntries(&b, sortedKeys(classes), 6, func(r rune) string {
+        return fmt.Sprintf("0x%04X: %d,", r, classes[r])
+    })
+    b.WriteString("}\n")
+    return b.Bytes()
+}
+
+func writeEntries(b *bytes.Buffer, keys []rune, perLine int, entry func(r rune) string) {
+    for i, r := range keys {
+        if i%perLine == 0 {
+            b.WriteString("    ")
+        } else {
+            b.WriteByte(' ')
+        }
+        b.WriteString(entry(r))
+        if i%perLine == perLine-1 || i == len(keys)-1 {
+            b.WriteByte('\n')
+        }
+    }
+}
@@ -0,0 +1,265 @@
+package password
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "strings"
+    "testing"
+
+    "AdventOfCode20/input"
+)
+
+const example = `1-3 a: abcde
+1-3 b: cdefg
+2-9 c: ccccccccc
+`
+
+func load(t *testing.T, database string) []Record {
+    t.Helper()
+    records, err := Load(strings.NewReader(database), input.FailOnError)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return records
+}
+
+func TestBuiltinPolicies(t *testing.T) {
+    records := load(t, example)
+    if valid := CountValid(records, SledRental()); valid != 2 {
+        t.Errorf("sled rental: got %d valid, want 2", valid)
+    }
+    if valid := CountValid(records, Toboggan()); valid != 1 {
+        t.Errorf("toboggan: got %d valid, want 1", valid)
+    }
+
+    // Position out of the password fails even if the other position holds the letter.
+    if violation := Toboggan().Check(load(t, "1-4 a: abc\n")[0]); violation == nil {
+        t.Error("toboggan: expected violation of position out of range")
+    }
+}
+
+func TestConfiguredPolicies(t *testing.T) {
+    policies, err := LoadPolicies(strings.NewReader(`[
+        {"name": "sled-rental", "type": "count"},
+        {"name": "few-c", "type": "count", "letter": "c", "min": 0, "max": 2},
+        {"name": "a-first", "type": "position", "letter": "a", "first": 1, "second": 2},
+        {"name": "long", "type": "regex", "pattern": "^.{6,}$"},
+        {"name": "no-vowels", "type": "forbidden", "letters": "aeiou"}
+    ]`))
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    expected := map[int][]string{
+        1: {"long", "no-vowels"},
+        2: {"sled-rental", "a-first", "long", "no-vowels"},
+        3: {"few-c", "a-first"},
+    }
+    for _, record := range load(t, example) {
+        var names []string
+        for _, violation := range Check(record, policies) {
+            names = append(names, violation.Policy)
+        }
+        if strings.Join(names, ",") != strings.Join(expected[record.Line], ",") {
+            t.Errorf("line %d: got failed policies %v, want %v", record.Line, names, expected[record.Line])
+        }
+    }
+}
+
+func TestInvalidPolicyConfig(t *testing.T) {
+    for _, config := range []string{
+        `[{"name": "x", "type": "length"}]`,
+        `[{"type": "count"}]`,
+        `[{"name": "x", "type": "count"}, {"name": "x", "type": "position"}]`,
+        `[{"name": "x", "type": "regex", "pattern": "("}]`,
+        `[{"name": "x", "type": "count", "limit": 3}]`,
+    } {
+        if _, err := LoadPolicies(strings.NewReader(config)); err == nil {
+            t.Errorf("%s: expected error", config)
+        }
+    }
+}
+
+func TestAudit(t *testing.T) {
+    records := load(t, example+"1-2 x: abcde\n9-9 c: ccccccccccc\n2-5 c: ab\n")
+    audit := NewAudit(records, []Policy{SledRental(), Toboggan()})
+
+    expected := map[string]map[Reason]int{
+        "sled-rental": {TooFew: 3, TooMany: 1},
+        "toboggan":    {NoPositionalMatch: 2, PositionalCollision: 2, IndexOutOfRange: 1},
+    }
+    for _, policy := range audit.Policies {
+        reasons := make(map[Reason]int)
+        for _, reason := range policy.Reasons {
+            reasons[reason.Reason] = reason.Count
+        }
+        for reason, count := range expected[policy.Name] {
+            if reasons[reason] != count {
+                t.Errorf("%s: got %d failures for %s, want %d", policy.Name, reasons[reason], reason, count)
+            }
+        }
+    }
+
+    if len(audit.Duplicates) != 1 || audit.Duplicates[0].Password != "abcde" ||
+        len(audit.Duplicates[0].Lines) != 2 {
+        t.Errorf("got duplicates %v, want abcde on lines 1 and 4", audit.Duplicates)
+    }
+    for _, count := range audit.RuleLetters {
+        if count.Letter == "c" && count.Count != 3 {
+            t.Errorf("got %d rules of letter c, want 3", count.Count)
+        }
+    }
+}
+
+func TestAuditMarkdownDuplicates(t *testing.T) {
+    records := load(t, "1-3 a: ab|c\n1-3 a: ab|c\n1-3 a: `a``b\n1-3 a: `a``b\n")
+    var output strings.Builder
+    if err := NewAudit(records, []Policy{SledRental()}).WriteMarkdown(&output); err != nil {
+        t.Fatal(err)
+    }
+    // Pipe would split the cell and backticks would end the code span.
+    for _, row := range []string{"| `ab\\|c` | 1 2 |\n", "| ``` `a``b ``` | 3 4 |\n"} {
+        if !strings.Contains(output.String(), row) {
+            t.Errorf("got %q, want row %q", output.String(), row)
+        }
+    }
+}
+
+func TestStream(t *testing.T) {
+    // Enough lines for many batches, with an invalid line now and then.
+    var database strings.Builder
+    for i := 0; i < 5000; i++ {
+        if i%777 == 0 {
+            fmt.Fprintln(&database, "invalid")
+        }
+        fmt.Fprintf(&database, "%d-%d %c: %s\n", i%3+1, i%5+3, 'a'+i%4, strings.Repeat("abcd", i%3+1))
+    }
+    policies := []Policy{SledRental(), Toboggan()}
+
+    var expected []string
+    _, err := Load(strings.NewReader(database.String()), func(err error) error {
+        expected = append(expected, err.Error())
+        return nil
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, record := range load(t, strings.ReplaceAll(database.String(), "invalid\n", "")) {
+        // Records loaded without the invalid lines are numbered differently, compare only the violations.
+        if violations := Check(record, policies); violations != nil {
+            expected = append(expected, fmt.Sprint(violations))
+        }
+    }
+
+    for _, workers := range []int{1, 4} {
+        var invalid, failed []string
+        err := Stream(context.Background(), strings.NewReader(database.String()), policies, workers,
+            func(err error) error {
+                invalid = append(invalid, err.Error())
+                return nil
+            },
+            func(record Record, violations []Violation) error {
+                if violations != nil {
+                    failed = append(failed, fmt.Sprint(violations))
+                }
+                return nil
+            })
+        if err != nil {
+            t.Fatal(err)
+        }
+        if got := append(invalid, failed...); strings.Join(got, "\n") != strings.Join(expected, "\n") {
+            t.Errorf("%d workers: stream differs from sequential validation", workers)
+        }
+    }
+
+    // Error of the function stops the stream right away.
+    stop := errors.New("stop")
+    seen, last := 0, 0
+    err = Stream(context.Background(), strings.NewReader(database.String()), policies, 4, input.Ignore,
+        func(record Record, violations []Violation) error {
+            seen++
+            if record.Line <= last {
+                t.Errorf("got line %d after line %d", record.Line, last)
+            }
+            last = record.Line
+            if seen == 100 {
+                return stop
+            }
+            return nil
+        })
+    if err != stop || seen != 100 {
+        t.Errorf("got error %v after %d records, want stop after 100", err, seen)
+    }
+}
+
+func TestUnicode(t *testing.T) {
+    // Thumbs up with skin tone, family joined by ZWJ, two flags and a letter.
+    emoji := "\U0001f44d\U0001f3fd" + "\U0001f468\u200d\U0001f469\u200d\U0001f467" + "\U0001f1e8\U0001f1ff" +
+        "\U0001f1f8\U0001f1f0" + "x"
+    for _, test := range []struct {
+        text      string
+        nfc, nfd  string
+        graphemes int
+    }{
+        {"abc", "abc", "abc", 3},
+        {"e\u0301te\u0301", "\u00e9t\u00e9", "e\u0301te\u0301", 3},
+        {"\u1ea4", "\u1ea4", "A\u0302\u0301", 1},
+        {"A\u0301\u0302", "\u00c1\u0302", "A\u0301\u0302", 1},
+        {"q\u0323\u0307", "q\u0323\u0307", "q\u0323\u0307", 1},
+        {"s\u0307\u0323", "\u1e69", "s\u0323\u0307", 1},
+        // Singletons decompose to other characters and they are never composed back.
+        {"\u212b", "\u00c5", "A\u030a", 1},
+        {"\u2126", "\u03a9", "\u03a9", 1},
+        {"\u1f71", "\u03ac", "\u03b1\u0301", 1},
+        {"\u0340", "\u0300", "\u0300", 1},
+        {"a\u0344", "\u00e4\u0301", "a\u0308\u0301", 1},
+        {emoji, emoji, emoji, 5},
+    } {
+        if nfc := Normalize(test.text, NFC); nfc != test.nfc {
+            t.Errorf("NFC of %q: got %q, want %q", test.text, nfc, test.nfc)
+        }
+        if nfd := Normalize(test.text, NFD); nfd != test.nfd {
+            t.Errorf("NFD of %q: got %q, want %q", test.text, nfd, test.nfd)
+        }
+        if graphemes := Graphemes(test.text); len(graphemes) != test.graphemes {
+            t.Errorf("graphemes of %q: got %q, want %d", test.text, graphemes, test.graphemes)
+        }
+    }
+
+    if Normalize("\u212b", NFC) != Normalize("\u00c5", NFC) {
+        t.Error("Angstrom sign and letter A with ring above are not the same in NFC")
+    }
+
+    // Letter written decomposed in the rule and precomposed in the password (and vice versa) is the same letter, on
+    // the same positions for both policies.
+    records := load(t, "1-2 e\u0301: \u00e9\u00e9x\n2-3 \u00e9: xe\u0301\u00e9\n2-3 \u00e9: x\u00e9e\n")
+    if valid := CountValid(records, SledRental()); valid != 2 {
+        t.Errorf("sled rental: got %d valid, want 2", valid)
+    }
+    if valid := CountValid(records, Toboggan()); valid != 1 {
+        t.Errorf("toboggan: got %d valid, want 1", valid)
+    }
+    exact := &CountRange{PolicyName: "exact", Text: TextOptions{Normalization: NoNormalization}}
+    if valid := CountValid(records, exact); valid != 0 {
+        t.Errorf("exact: got %d valid, want 0", valid)
+    }
+
+    if _, err := ParseRecord(input.Line{Number: 1, Text: "1-2 ab: abc"}); err == nil {
+        t.Error("expected error of rule letter with two characters")
+    }
+    policies, err := LoadPolicies(strings.NewReader(`[
+        {"name": "no-a", "type": "forbidden", "letters": "A", "fold_case": true},
+        {"name": "no-bare-e", "type": "forbidden", "letters": "e", "normalization": "nfd"},
+        {"name": "x-first", "type": "regex", "pattern": "^x", "fold_case": true}
+    ]`))
+    if err != nil {
+        t.Fatal(err)
+    }
+    for password, expected := range map[string]int{"Abc": 2, "xa\u00e9": 1, "xyz": 0, "Xyz": 0, "\u00e9e": 2} {
+        record := Record{Password: password}
+        if violations := Check(record, policies); len(violations) != expected {
+            t.Errorf("%q: got violations %v, want %d", password, violations, expected)
+        }
+    }
+}
@@ -0,0 +1,286 @@
+package password
+
+import (
+    "encoding/json"
+    "fmt"
+    "io"
+    "regexp"
+)
+
+// Reason classifies violations of the policies.
+type Reason string
+
+const (
+    TooFew              Reason = "too few"
+    TooMany             Reason = "too many"
+    PositionalCollision Reason = "positional collision"
+    NoPositionalMatch   Reason = "no positional match"
+    IndexOutOfRange     Reason = "index out of range"
+    PatternMismatch     Reason = "pattern mismatch"
+    ForbiddenLetter     Reason = "forbidden letter"
+)
+
+// Violation describes why the record does not satisfy the policy.
+type Violation struct {
+    Policy  string
+    Reason  Reason
+    Message string
+}
+
+func (v Violation) String() string {
+    return fmt.Sprintf("%s: %s", v.Policy, v.Message)
+}
+
+// Policy decides whether the password of the record is valid.
+type Policy interface {
+    Name() string
+    // Check returns nil for valid record, otherwise the description of the violation.
+    Check(record Record) *Violation
+}
+
+// SledRental is the policy of the sled rental place down the street (part 1 of the puzzle): the letter of the record
+// rule has to appear at least Min and at most Max times.
+func SledRental() Policy {
+    return &CountRange{PolicyName: "sled-rental"}
+}
+
+// Toboggan is the policy of the Official Toboggan Corporate Authentication System (part 2 of the puzzle): the letter of
+// the record rule has to appear on exactly one of positions Min and Max (indexed from 1).
+func Toboggan() Policy {
+    return &PositionalXOR{PolicyName: "toboggan"}
+}
+
+// CountRange requires the number of occurrences of the letter to be within the range. Empty letter means the rule of
+// the record is used instead of the letter and range of the policy.
+type CountRange struct {
+    PolicyName string
+    Letter     string
+    Min        int
+    Max        int
+    Text       TextOptions
+}
+
+func (p *CountRange) Name() string {
+    return p.PolicyName
+}
+
+func (p *CountRange) Check(record Record) *Violation {
+    rule := Rule{Min: p.Min, Max: p.Max, Letter: p.Letter}
+    if p.Letter == "" {
+        rule = record.Rule
+    }
+
+    count := p.Text.Count(record.Password, rule.Letter)
+    reason := TooFew
+    switch {
+    case count > rule.Max:
+        reason = TooMany
+    case count >= rule.Min:
+        return nil
+    }
+    return &Violation{
+        Policy:  p.PolicyName,
+        Reason:  reason,
+        Message: fmt.Sprintf("%q appears %d times, expected %d-%d", rule.Letter, count, rule.Min, rule.Max),
+    }
+}
+
+// PositionalXOR requires the letter on exactly one of two positions (indexed from 1). Empty letter means the rule of
+// the record is used, its minimum and maximum being the positions. Positions count characters (graphemes) of the
+// normalized password, the same ones CountRange counts.
+type PositionalXOR struct {
+    PolicyName string
+    Letter     string
+    First      int
+    Second     int
+    Text       TextOptions
+}
+
+func (p *PositionalXOR) Name() string {
+    return p.PolicyName
+}
+
+func (p *PositionalXOR) Check(record Record) *Violation {
+    letter, first, second := p.Letter, p.First, p.Second
+    if p.Letter == "" {
+        letter, first, second = record.Rule.Letter, record.Rule.Min, record.Rule.Max
+    }
+
+    violation := func(reason Reason, format string, args ...interface{}) *Violation {
+        return &Violation{Policy: p.PolicyName, Reason: reason, Message: fmt.Sprintf(format, args...)}
+    }
+    // Both positions have to exist, even if the letter is found on the first one.
+    graphemes := p.Text.Graphemes(record.Password)
+    for _, position := range []int{first, second} {
+        if position < 1 || position > len(graphemes) {
+            return violation(IndexOutOfRange, "position %d is out of password of length %d", position,
+                len(graphemes))
+        }
+    }
+
+    letter = Normalize(letter, p.Text.Normalization)
+    firstMatch := p.Text.Equal(graphemes[first-1], letter)
+    secondMatch := p.Text.Equal(graphemes[second-1], letter)
+    switch {
+    case firstMatch && secondMatch:
+        return violation(PositionalCollision, "%q is on both positions %d and %d", letter, first, second)
+    case !firstMatch && !secondMatch:
+        return violation(NoPositionalMatch, "%q is on neither position %d nor %d", letter, first, second)
+    }
+    return nil
+}
+
+// RegexMatch requires the normalized password to match the regular expression. Case folding is part of the pattern
+// (flag "(?i)"), configured policies with fold_case get it added.
+type RegexMatch struct {
+    PolicyName string
+    Pattern    *regexp.Regexp
+    Text       TextOptions
+}
+
+func (p *RegexMatch) Name() string {
+    return p.PolicyName
+}
+
+func (p *RegexMatch) Check(record Record) *Violation {
+    if !p.Pattern.MatchString(Normalize(record.Password, p.Text.Normalization)) {
+        return &Violation{
+            Policy:  p.PolicyName,
+            Reason:  PatternMismatch,
+            Message: fmt.Sprintf("does not match %q", p.Pattern),
+        }
+    }
+    return nil
+}
+
+// ForbiddenLetters requires the password not to contain any of the letters (compared as whole characters, so a
+// forbidden "e" does not forbid "é").
+type ForbiddenLetters struct {
+    PolicyName string
+    Letters    string
+    Text       TextOptions
+}
+
+func (p *ForbiddenLetters) Name() string {
+    return p.PolicyName
+}
+
+func (p *ForbiddenLetters) Check(record Record) *Violation {
+    forbidden := p.Text.Graphemes(p.Letters)
+    for _, grapheme := range p.Text.Graphemes(record.Password) {
+        for _, letter := range forbidden {
+            if p.Text.Equal(grapheme, letter) {
+                return &Violation{
+                    Policy:  p.PolicyName,
+                    Reason:  ForbiddenLetter,
+                    Message: fmt.Sprintf("contains forbidden %q", grapheme),
+                }
+            }
+        }
+    }
+    return nil
+}
+
+// Declarative definition of a policy in the config file. Fields used depend on the type of the policy, normalization
+// and case folding apply to all of them.
+type policyConfig struct {
+    Name          string `json:"name"`
+    Type          string `json:"type"`
+    Letter        string `json:"letter"`
+    Min           int    `json:"min"`
+    Max           int    `json:"max"`
+    First         int    `json:"first"`
+    Second        int    `json:"second"`
+    Pattern       string `json:"pattern"`
+    Letters       string `json:"letters"`
+    Normalization string `json:"normalization"`
+    FoldCase      bool   `json:"fold_case"`
+}
+
+// LoadPolicies reads policies defined in JSON config: an array of objects with name and type of the policy and its
+// parameters.
+//
+//     [
+//         {"name": "sled-rental", "type": "count"},
+//         {"name": "few-x", "type": "count", "letter": "x", "min": 0, "max": 2},
+//         {"name": "toboggan", "type": "position"},
+//         {"name": "long", "type": "regex", "pattern": "^.{8,}$"},
+//         {"name": "no-vowels", "type": "forbidden", "letters": "aeiou", "fold_case": true},
+//         {"name": "exact-z", "type": "count", "letter": "ž", "min": 1, "max": 1, "normalization": "none"}
+//     ]
+//
+// Count and position policies without letter use the rule of each record. Letters and passwords are compared in NFC
+// normalization unless "nfd" or "none" is given.
+func LoadPolicies(reader io.Reader) ([]Policy, error) {
+    var configs []policyConfig
+    decoder := json.NewDecoder(reader)
+    decoder.DisallowUnknownFields()
+    if err := decoder.Decode(&configs); err != nil {
+        return nil, fmt.Errorf("invalid policy config: %w", err)
+    }
+
+    names := make(map[string]bool)
+    var policies []Policy
+    for i, config := range configs {
+        if config.Name == "" {
+            return nil, fmt.Errorf("policy #%d has no name", i+1)
+        }
+        if names[config.Name] {
+            return nil, fmt.Errorf("policy %q is defined twice", config.Name)
+        }
+        names[config.Name] = true
+
+        policy, err := newPolicy(config)
+        if err != nil {
+            return nil, fmt.Errorf("policy %q: %w", config.Name, err)
+        }
+        policies = append(policies, policy)
+    }
+    return policies, nil
+}
+
+func newPolicy(config policyConfig) (Policy, error) {
+    text := TextOptions{FoldCase: config.FoldCase}
+    if config.Normalization != "" {
+        normalization, err := ParseNormalization(config.Normalization)
+        if err != nil {
+            return nil, err
+        }
+        text.Normalization = normalization
+    }
+    if config.Letter != "" && len(text.Graphemes(config.Letter)) != 1 {
+        return nil, fmt.Errorf("letter %q is not a single character", config.Letter)
+    }
+
+    switch config.Type {
+    case "count":
+        if config.Letter != "" && config.Min > config.Max {
+            return nil, fmt.Errorf("minimum %d is greater than maximum %d", config.Min, config.Max)
+        }
+        return &CountRange{PolicyName: config.Name, Letter: config.Letter, Min: config.Min, Max: config.Max,
+            Text: text}, nil
+    case "position":
+        if config.Letter != "" && (config.First < 1 || config.Second < 1) {
+            return nil, fmt.Errorf("positions are indexed from 1")
+        }
+        return &PositionalXOR{PolicyName: config.Name, Letter: config.Letter, First: config.First,
+            Second: config.Second, Text: text}, nil
+    case "regex":
+        source := config.Pattern
+        if text.FoldCase {
+            source = "(?i)" + source
+        }
+        pattern, err := regexp.Compile(source)
+        if err != nil {
+            return nil, err
+        }
+        return &RegexMatch{PolicyName: config.Name, Pattern: pattern, Text: text}, nil
+    case "forbidden":
+        if config.Letters == "" {
+            return nil, fmt.Errorf("no forbidden letters")
+        }
+        return &ForbiddenLetters{PolicyName: config.Name, Letters: config.Letters, Text: text}, nil
+    default:
+        return nil, fmt.Errorf("unknown type %q (use count, position, regex or forbidden)", config.Type)
+    }
+}
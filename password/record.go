@@ -0,0 +1,80 @@
+// Package password implements the password database from Day 2: records with their corporate policy rules and an
+// engine validating them against built-in or declaratively configured policies.
+package password
+
+import (
+    "fmt"
+    "io"
+
+    "AdventOfCode20/input"
+)
+
+// RecordRegex matches a line of the database. Letter of the rule can be any character, including letters with
+// combining marks, it is checked to be a single character by ParseRecord.
+const RecordRegex = `(?P<min>\d*)-(?P<max>\d*)\s(?P<letter>[^\s:]+):\s(?P<password>.*)`
+
+var recordPattern = input.MustCompile(RecordRegex)
+
+// Rule is the policy part of the record ("1-3 a"). Its meaning depends on the policy which interprets it.
+type Rule struct {
+    Min    int
+    Max    int
+    Letter string
+}
+
+func (r Rule) String() string {
+    return fmt.Sprintf("%d-%d %s", r.Min, r.Max, r.Letter)
+}
+
+// Record is a single line of the password database.
+type Record struct {
+    Line     int
+    Rule     Rule
+    Password string
+}
+
+func (r Record) String() string {
+    return fmt.Sprintf("%v: %s", r.Rule, r.Password)
+}
+
+// Load reads all records of the database. Rows that do not match the record format are reported to the error
+// handler.
+func Load(reader io.Reader, onError input.ErrorHandler) ([]Record, error) {
+    var records []Record
+    err := input.EachLine(reader, func(line input.Line) error {
+        record, err := ParseRecord(line)
+        if err != nil {
+            return onError(err)
+        }
+        records = append(records, record)
+        return nil
+    })
+
+    return records, err
+}
+
+// ParseRecord converts the line with password record components into final structure.
+func ParseRecord(line input.Line) (Record, error) {
+    var parts struct {
+        Min      int
+        Max      int
+        Letter   string
+        Password string
+    }
+    if err := recordPattern.Extract(line, &parts); err != nil {
+        return Record{}, err
+    }
+    if len(Graphemes(parts.Letter)) != 1 {
+        return Record{}, input.Errorf(line, 0, "letter %q is not a single character", parts.Letter)
+    }
+
+    return Record{
+        Line: line.Number,
+        Rule: Rule{
+            Min:    parts.Min,
+            Max:    parts.Max,
+            Letter: parts.Letter,
+        },
+        Password: parts.Password,
+    }, nil
+}
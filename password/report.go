@@ -0,0 +1,69 @@
+package password
+
+import (
+    "bufio"
+    "fmt"
+    "io"
+)
+
+// Check returns violations of all the policies the record fails.
+func Check(record Record, policies []Policy) []Violation {
+    var violations []Violation
+    for _, policy := range policies {
+        if violation := policy.Check(record); violation != nil {
+            violations = append(violations, *violation)
+        }
+    }
+    return violations
+}
+
+// CountValid returns the number of records satisfying the policy.
+func CountValid(records []Record, policy Policy) int {
+    counter := 0
+    for _, record := range records {
+        if policy.Check(record) == nil {
+            counter++
+        }
+    }
+    return counter
+}
+
+// Report writes the failing records as they are added, so it can be used with records streamed from a database of
+// any size. The summary is written when the report is closed.
+type Report struct {
+    bw       *bufio.Writer
+    policies []Policy
+    total    int
+    failures int
+    failed   map[string]int
+}
+
+// NewReport creates a report of the policies written to the writer.
+func NewReport(w io.Writer, policies []Policy) *Report {
+    return &Report{bw: bufio.NewWriter(w), policies: policies, failed: make(map[string]int)}
+}
+
+// Add counts the record and writes it if it has any violations.
+func (r *Report) Add(record Record, violations []Violation) {
+    r.total++
+    if len(violations) == 0 {
+        return
+    }
+
+    r.failures++
+    fmt.Fprintf(r.bw, "line %d: %v\n", record.Line, record)
+    for _, violation := range violations {
+        fmt.Fprintf(r.bw, "    %v\n", violation)
+        r.failed[violation.Policy]++
+    }
+}
+
+// Close writes the number of failures of each policy and flushes the report.
+func (r *Report) Close() error {
+    fmt.Fprintf(r.bw, "%d of %d record(s) fail some policy\n", r.failures, r.total)
+    for _, policy := range r.policies {
+        fmt.Fprintf(r.bw, "    %s: %d failed, %d passed\n", policy.Name(), r.failed[policy.Name()],
+            r.total-r.failed[policy.Name()])
+    }
+    return r.bw.Flush()
+}
@@ -0,0 +1,126 @@
+package password
+
+import (
+    "context"
+    "io"
+
+    "AdventOfCode20/input"
+)
+
+// Number of lines parsed and checked by a worker at once. Batches keep the channel traffic low, while the number of
+// batches in flight bounds the memory used by the stream.
+const streamBatchSize = 256
+
+// Lines of the database handed to a worker, and the channel the worker sends their results to.
+type streamBatch struct {
+    lines   []input.Line
+    results chan []streamResult
+}
+
+// Result of a single line: either the checked record or the error of an invalid line.
+type streamResult struct {
+    record     Record
+    violations []Violation
+    err        error
+}
+
+// Stream reads the database line by line, parses and checks the records against the policies in given number of
+// goroutines and calls the function with every record and the violations it has (nil if the record passes all the
+// policies). Invalid lines are reported to the error handler. Both are called from the calling goroutine, in order of
+// the lines, so the output of the stream is the same as the output of sequential validation.
+//
+// Only a few batches of lines are held at once, so the memory used does not depend on the size of the database. The
+// stream stops on the first error returned by the handler or the function, or once the context is done. Policies have
+// to be safe for concurrent use (all the policies of the package are).
+func Stream(ctx context.Context, reader io.Reader, policies []Policy, workers int, onError input.ErrorHandler,
+    f func(record Record, violations []Violation) error) error {
+    if workers < 1 {
+        workers = 1
+    }
+    ctx, cancel := context.WithCancel(ctx)
+    defer cancel()
+
+    // Batches go to the workers in any order, but the consumer takes them from pending in order of the lines. Capacity
+    // of pending limits the number of batches in flight.
+    jobs := make(chan *streamBatch)
+    pending := make(chan *streamBatch, 2*workers)
+    for i := 0; i < workers; i++ {
+        go func() {
+            for batch := range jobs {
+                batch.results <- checkLines(batch.lines, policies)
+            }
+        }()
+    }
+
+    readErr := make(chan error, 1)
+    go func() {
+        defer close(pending)
+        defer close(jobs)
+
+        send := func(lines []input.Line) error {
+            // Results are buffered, so workers never wait for the consumer, even if it has already stopped.
+            batch := &streamBatch{lines: lines, results: make(chan []streamResult, 1)}
+            select {
+            case pending <- batch:
+            case <-ctx.Done():
+                return ctx.Err()
+            }
+            select {
+            case jobs <- batch:
+            case <-ctx.Done():
+                return ctx.Err()
+            }
+            return nil
+        }
+
+        lines := make([]input.Line, 0, streamBatchSize)
+        err := input.EachLine(reader, func(line input.Line) error {
+            lines = append(lines, line)
+            if len(lines) < streamBatchSize {
+                return nil
+            }
+            batch := lines
+            lines = make([]input.Line, 0, streamBatchSize)
+            return send(batch)
+        })
+        if err == nil && len(lines) > 0 {
+            err = send(lines)
+        }
+        readErr <- err
+    }()
+
+    for batch := range pending {
+        var results []streamResult
+        select {
+        case results = <-batch.results:
+        case <-ctx.Done():
+            return ctx.Err()
+        }
+        for _, result := range results {
+            var err error
+            if result.err != nil {
+                err = onError(result.err)
+            } else {
+                err = f(result.record, result.violations)
+            }
+            if err != nil {
+                return err
+            }
+        }
+    }
+    return <-readErr
+}
+
+func checkLines(lines []input.Line, policies []Policy) []streamResult {
+    results := make([]streamResult, len(lines))
+    for i, line := range lines {
+        record, err := ParseRecord(line)
+        if err != nil {
+            results[i].err = err
+            continue
+        }
+        results[i].record = record
+        results[i].violations = Check(record, policies)
+    }
+    return results
+}
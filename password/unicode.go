@@ -0,0 +1,200 @@
+package password
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+    "unicode"
+)
+
+//go:generate go run gen_tables.go -ucd UnicodeData.txt
+
+// Normalization is the Unicode normalization form applied to letters and passwords before they are compared. Only
+// canonical decompositions of Latin, Greek and Cyrillic letters, combining marks and letterlike symbols are known (see
+// normalize_tables.go), other characters are kept as they are.
+type Normalization int
+
+const (
+    // NFC composes letters with their marks, e.g. "e" followed by combining acute accent becomes "é" (the default).
+    NFC Normalization = iota
+    // NFD decomposes letters into base letter and combining marks.
+    NFD
+    // NoNormalization compares the characters exactly as they are written.
+    NoNormalization
+)
+
+func (n Normalization) String() string {
+    switch n {
+    case NFC:
+        return "nfc"
+    case NFD:
+        return "nfd"
+    case NoNormalization:
+        return "none"
+    default:
+        return fmt.Sprintf("Normalization(%d)", int(n))
+    }
+}
+
+// ParseNormalization returns the normalization of given name (nfc, nfd or none).
+func ParseNormalization(name string) (Normalization, error) {
+    for _, n := range []Normalization{NFC, NFD, NoNormalization} {
+        if strings.EqualFold(name, n.String()) {
+            return n, nil
+        }
+    }
+    return 0, fmt.Errorf("unknown normalization %q (use nfc, nfd or none)", name)
+}
+
+// Normalize converts the text into the normalization form.
+func Normalize(text string, form Normalization) string {
+    if form == NoNormalization || isASCII(text) {
+        return text
+    }
+
+    var runes []rune
+    for _, r := range text {
+        runes = decompose(runes, r)
+    }
+    reorderMarks(runes)
+    if form == NFC {
+        runes = compose(runes)
+    }
+    return string(runes)
+}
+
+func isASCII(text string) bool {
+    for i := 0; i < len(text); i++ {
+        if text[i] > unicode.MaxASCII {
+            return false
+        }
+    }
+    return true
+}
+
+// Appends the full canonical decomposition of the rune.
+func decompose(runes []rune, r rune) []rune {
+    if parts, ok := decompositions[r]; ok {
+        for _, part := range parts {
+            runes = decompose(runes, part)
+        }
+        return runes
+    }
+    return append(runes, r)
+}
+
+// Puts every run of combining marks into canonical order (stable sort by their combining classes).
+func reorderMarks(runes []rune) {
+    for start := 0; start < len(runes); start++ {
+        end := start
+        for end < len(runes) && combiningClasses[runes[end]] > 0 {
+            end++
+        }
+        if end-start > 1 {
+            marks := runes[start:end]
+            sort.SliceStable(marks, func(i, j int) bool {
+                return combiningClasses[marks[i]] < combiningClasses[marks[j]]
+            })
+        }
+        start = end
+    }
+}
+
+// Canonical compositions, the inverse of the decompositions table. Singletons (e.g. Angstrom sign) and characters
+// decomposing into marks are never composed back, as they are excluded from composition by Unicode.
+var compositions = func() map[[2]rune]rune {
+    result := make(map[[2]rune]rune, len(decompositions))
+    for composite, parts := range decompositions {
+        if len(parts) == 2 && combiningClasses[composite] == 0 && combiningClasses[parts[0]] == 0 {
+            result[[2]rune{parts[0], parts[1]}] = composite
+        }
+    }
+    return result
+}()
+
+// Composes decomposed and reordered runes. Mark is composed with the last starter unless another mark of the same or
+// higher combining class stands between them.
+func compose(runes []rune) []rune {
+    result := make([]rune, 0, len(runes))
+    starter, lastClass := -1, -1
+    for _, r := range runes {
+        class := int(combiningClasses[r])
+        if starter >= 0 && (lastClass == -1 || lastClass != 0 && lastClass < class) {
+            if composite, ok := compositions[[2]rune{result[starter], r}]; ok {
+                result[starter] = composite
+                continue
+            }
+        }
+        if class == 0 {
+            starter, lastClass = len(result), -1
+        } else {
+            lastClass = class
+        }
+        result = append(result, r)
+    }
+    return result
+}
+
+// Joiner gluing two characters into one (e.g. emoji sequences).
+const zeroWidthJoiner = '\u200d'
+
+// Graphemes splits the text into user-perceived characters: base character followed by its combining marks, variation
+// selectors and emoji modifiers, characters joined by zero width joiner and pairs of regional indicators (flags).
+// This is a simplification of Unicode text segmentation, but it covers letters of passwords well.
+func Graphemes(text string) []string {
+    var graphemes []string
+    start, joined, indicators := 0, false, 0
+    for i, r := range text {
+        indicator := unicode.Is(unicode.Regional_Indicator, r)
+        extends := joined || unicode.Is(unicode.M, r) || unicode.Is(unicode.Variation_Selector, r) ||
+            isEmojiModifier(r) || r == zeroWidthJoiner || indicator && indicators%2 == 1
+        if !extends && i > 0 {
+            graphemes = append(graphemes, text[start:i])
+            start, indicators = i, 0
+        }
+        joined = r == zeroWidthJoiner
+        if indicator {
+            indicators++
+        }
+    }
+    if start < len(text) {
+        graphemes = append(graphemes, text[start:])
+    }
+    return graphemes
+}
+
+func isEmojiModifier(r rune) bool {
+    return r >= 0x1F3FB && r <= 0x1F3FF
+}
+
+// TextOptions tell policies how to compare letters of passwords.
+type TextOptions struct {
+    Normalization Normalization
+    // FoldCase makes upper and lower case letters equal.
+    FoldCase bool
+}
+
+// Graphemes returns the characters of normalized text.
+func (o TextOptions) Graphemes(text string) []string {
+    return Graphemes(Normalize(text, o.Normalization))
+}
+
+// Equal compares two normalized characters.
+func (o TextOptions) Equal(a, b string) bool {
+    if o.FoldCase {
+        return strings.EqualFold(a, b)
+    }
+    return a == b
+}
+
+// Count returns the number of characters of the text equal to the letter.
+func (o TextOptions) Count(text, letter string) int {
+    letter = Normalize(letter, o.Normalization)
+    count := 0
+    for _, grapheme := range o.Graphemes(text) {
+        if o.Equal(grapheme, letter) {
+            count++
+        }
+    }
+    return count
+}
@@ -0,0 +1,52 @@
+// Package toboggan rides the toboggan down the slope map of Advent of Code 2020 day 3: it counts trees on the rides
+// of various slopes (including fractional ones), searches for the best slope and draws the rides.
+package toboggan
+
+import (
+    "fmt"
+    "io"
+
+    "AdventOfCode20/grid"
+    "AdventOfCode20/input"
+)
+
+// Square is a single position of the map.
+type Square rune
+
+const (
+    OpenSquare Square = '.'
+    Tree       Square = '#'
+)
+
+// Map of the slope repeats infinitely to the right, which is covered by wrapped grid. Vertical wrapping is never used,
+// as the ride ends at the bottom of the map.
+type Map struct {
+    squares *grid.Grid[Square]
+}
+
+// Load reads map rows where "#" indicates tree and "." empty space. Rows with other characters are reported to the
+// error handler.
+func Load(reader io.Reader, onError input.ErrorHandler) (*Map, error) {
+    squares, err := grid.Decode(reader, grid.Wrapped, func(char rune) (Square, error) {
+        switch Square(char) {
+        case Tree, OpenSquare:
+            return Square(char), nil
+        }
+        return 0, fmt.Errorf("unknown square %q", char)
+    }, onError)
+    if err != nil {
+        return nil, err
+    }
+
+    return &Map{squares: squares}, nil
+}
+
+// Width returns the number of columns of the map before it repeats.
+func (m *Map) Width() int {
+    return m.squares.Width()
+}
+
+// Height returns the number of rows of the map.
+func (m *Map) Height() int {
+    return m.squares.Height()
+}
@@ -0,0 +1,179 @@
+package toboggan
+
+import (
+    "bufio"
+    "errors"
+    "fmt"
+    "image"
+    "image/color"
+    "image/png"
+    "io"
+
+    "AdventOfCode20/grid"
+)
+
+// Ride of the toboggan down the slope, with all the squares it passes.
+type Ride struct {
+    Slope Slope
+    path  []grid.Point
+}
+
+// Ride returns the ride of the slope to be drawn on the map.
+func (m *Map) Ride(s Slope, rounding Rounding) Ride {
+    return Ride{Slope: s, path: m.Path(s, rounding)}
+}
+
+// Part of the infinite map covered by the rides: columns from the leftmost to the rightmost square passed, at least the
+// original map.
+type viewport struct {
+    left   int
+    right  int
+    height int
+    // Index of the first ride passing each square, later rides do not overwrite it. The start is never marked.
+    marks map[grid.Point]int
+}
+
+func (m *Map) viewport(rides []Ride) viewport {
+    v := viewport{right: m.squares.Width() - 1, height: m.squares.Height(), marks: make(map[grid.Point]int)}
+    for i, r := range rides {
+        for _, position := range r.path {
+            if position.X < v.left {
+                v.left = position.X
+            }
+            if position.X > v.right {
+                v.right = position.X
+            }
+            if _, ok := v.marks[position]; !ok && position != start {
+                v.marks[position] = i
+            }
+        }
+    }
+    return v
+}
+
+// ANSI colors of the rides (repeated when there are more rides) and the reset sequence.
+var (
+    rideEscapes = []string{"\x1b[1;31m", "\x1b[1;34m", "\x1b[1;35m", "\x1b[1;33m", "\x1b[1;36m", "\x1b[1;32m"}
+    resetEscape = "\x1b[0m"
+)
+
+// WriteASCII writes the map with the rides drawn over it as in the puzzle text: trees hit are marked by X and open
+// squares passed by O, the starting square is left unmarked. With colors the marks of every ride have their own ANSI
+// color, otherwise the rides cannot be told apart where they cross. Legend of the rides with their trees follows the
+// map.
+func (m *Map) WriteASCII(w io.Writer, rides []Ride, colors bool) error {
+    bw := bufio.NewWriter(w)
+    v := m.viewport(rides)
+    for y := 0; y < v.height; y++ {
+        for x := v.left; x <= v.right; x++ {
+            position := grid.Point{X: x, Y: y}
+            square, _ := m.squares.Get(position)
+            i, marked := v.marks[position]
+            if !marked {
+                bw.WriteRune(rune(square))
+                continue
+            }
+
+            mark := "O"
+            if square == Tree {
+                mark = "X"
+            }
+            if colors {
+                mark = rideEscapes[i%len(rideEscapes)] + mark + resetEscape
+            }
+            bw.WriteString(mark)
+        }
+        bw.WriteByte('\n')
+    }
+
+    for i, r := range rides {
+        name := fmt.Sprintf("ride %d", i+1)
+        if colors {
+            name = rideEscapes[i%len(rideEscapes)] + name + resetEscape
+        }
+        fmt.Fprintf(bw, "%s: %v, %d trees\n", name, r.Slope, m.countPathTrees(r.path))
+    }
+    return bw.Flush()
+}
+
+// Colors of the PNG image: snow, trees and the rides (repeated when there are more rides).
+var (
+    snowColor  = color.RGBA{R: 0xf2, G: 0xf5, B: 0xf8, A: 0xff}
+    treeColor  = color.RGBA{R: 0x2e, G: 0x6b, B: 0x3a, A: 0xff}
+    rideColors = []color.RGBA{
+        {R: 0xd6, G: 0x27, B: 0x28, A: 0xff},
+        {R: 0x1f, G: 0x5f, B: 0xd1, A: 0xff},
+        {R: 0x9c, G: 0x2f, B: 0xb5, A: 0xff},
+        {R: 0xe0, G: 0x8e, B: 0x0b, A: 0xff},
+        {R: 0x0f, G: 0x9b, B: 0xa8, A: 0xff},
+        {R: 0x11, G: 0x11, B: 0x11, A: 0xff},
+    }
+)
+
+// Smallest square size of the image which can still show the marks.
+const minSquareSize = 3
+
+// MaxImagePixels limits the size of the image, so that rides going far to the side (e.g. 1000 right and 1 down) or
+// large squares do not run out of memory. Image takes 4 bytes per pixel.
+const MaxImagePixels = 1 << 24
+
+// ErrImageTooLarge is returned when the image would have more than MaxImagePixels pixels.
+var ErrImageTooLarge = errors.New("image is too large")
+
+// Draws the map with the rides into an image, every square being size×size pixels. Trees hit are crossed out and
+// open squares passed are outlined, both in the color of the ride. Size of the image is checked before it is
+// allocated.
+func (m *Map) drawImage(rides []Ride, size int) (*image.RGBA, error) {
+    if size < minSquareSize {
+        size = minSquareSize
+    }
+    v := m.viewport(rides)
+    columns := v.right - v.left + 1
+    if size > MaxImagePixels || columns*v.height > MaxImagePixels/(size*size) {
+        return nil, fmt.Errorf("%w: %d×%d squares of %d pixels, at most %d pixels allowed", ErrImageTooLarge,
+            columns, v.height, size, MaxImagePixels)
+    }
+    img := image.NewRGBA(image.Rect(0, 0, columns*size, v.height*size))
+    thickness := size/6 + 1
+
+    for y := 0; y < v.height; y++ {
+        for x := v.left; x <= v.right; x++ {
+            position := grid.Point{X: x, Y: y}
+            square, _ := m.squares.Get(position)
+            background := snowColor
+            if square == Tree {
+                background = treeColor
+            }
+            i, marked := v.marks[position]
+
+            left, top := (x-v.left)*size, y*size
+            for dy := 0; dy < size; dy++ {
+                for dx := 0; dx < size; dx++ {
+                    c := background
+                    if marked && isMarkPixel(square == Tree, dx, dy, size, thickness) {
+                        c = rideColors[i%len(rideColors)]
+                    }
+                    img.SetRGBA(left+dx, top+dy, c)
+                }
+            }
+        }
+    }
+    return img, nil
+}
+
+// Decides whether the pixel of the square belongs to the X mark (both diagonals) or the O mark (the outline).
+func isMarkPixel(cross bool, dx, dy, size, thickness int) bool {
+    if cross {
+        return abs(dx-dy) < thickness || abs(dx+dy-(size-1)) < thickness
+    }
+    return dx < thickness || dy < thickness || dx >= size-thickness || dy >= size-thickness
+}
+
+// WritePNG encodes the image of the map with the rides as PNG, every square being size×size pixels (at least 3).
+func (m *Map) WritePNG(w io.Writer, rides []Ride, size int) error {
+    img, err := m.drawImage(rides, size)
+    if err != nil {
+        return err
+    }
+    return png.Encode(w, img)
+}
@@ -0,0 +1,86 @@
+package toboggan
+
+import (
+    "errors"
+    "fmt"
+)
+
+// Bounds of the slopes tried by the search. Right movement is any Fraction between the minimum and maximum with
+// denominator up to the maximum one (1 allows whole numbers only).
+type Bounds struct {
+    MinRight       int
+    MaxRight       int
+    MaxDown        int
+    MaxDenominator int
+}
+
+func (b Bounds) validate() error {
+    switch {
+    case b.MinRight > b.MaxRight:
+        return fmt.Errorf("minimum right movement %d is greater than maximum %d", b.MinRight, b.MaxRight)
+    case b.MaxDown < 1:
+        return errors.New("maximum down movement has to be positive")
+    case b.MaxDenominator < 1:
+        return errors.New("maximum denominator has to be positive")
+    }
+    return nil
+}
+
+// Goal of the slope search.
+type Goal int
+
+const (
+    FewestTrees Goal = iota
+    MostTrees
+)
+
+// Result is the slope found by the search together with the trees it encounters on the rows it visits.
+type Result struct {
+    Slope Slope
+    Trees int
+    Rows  int
+}
+
+// Compares the shares of trees on the visited rows of two results, negative when the first one has lower share.
+func compareShares(a, b Result) int {
+    return a.Trees*b.Rows - b.Trees*a.Rows
+}
+
+// Decides whether the result is better than the best one so far.
+func (g Goal) better(result, best Result) bool {
+    share := compareShares(result, best)
+    if g == MostTrees {
+        share = -share
+    }
+    return share < 0 || share == 0 && result.Rows > best.Rows
+}
+
+// FindSlope tries all slopes within the bounds and returns the one with the lowest (or the highest) share of trees on
+// the rows it visits. Raw tree counts would favour steep slopes, which visit only a few rows, and slopes skipping the
+// rows between columns. Equal shares are broken in favour of the ride visiting more rows, then of the simplest slope,
+// which is the first one in order of increasing down movement, denominator and right movement.
+func (m *Map) FindSlope(bounds Bounds, goal Goal, rounding Rounding) (Result, error) {
+    if err := bounds.validate(); err != nil {
+        return Result{}, err
+    }
+
+    var best Result
+    found := false
+    for down := 1; down <= bounds.MaxDown; down++ {
+        for den := 1; den <= bounds.MaxDenominator; den++ {
+            for num := bounds.MinRight * den; num <= bounds.MaxRight*den; num++ {
+                // Other fractions were already tried with smaller denominator.
+                if gcd(abs(num), den) != 1 {
+                    continue
+                }
+                s := Slope{Right: Fraction{num: num, den: den}, Down: down}
+                path := m.Path(s, rounding)
+                result := Result{Slope: s, Trees: m.countPathTrees(path), Rows: len(path)}
+                if !found || goal.better(result, best) {
+                    best, found = result, true
+                }
+            }
+        }
+    }
+    return best, nil
+}
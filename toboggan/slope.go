@@ -0,0 +1,171 @@
+package toboggan
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+
+    "AdventOfCode20/grid"
+)
+
+// Fraction is exact rational number num/den, always in lowest terms with positive denominator.
+type Fraction struct {
+    num int
+    den int
+}
+
+// NewFraction returns num/den in lowest terms. Denominator must not be zero.
+func NewFraction(num, den int) Fraction {
+    if den == 0 {
+        panic("toboggan: fraction with zero denominator")
+    }
+    if den < 0 {
+        num, den = -num, -den
+    }
+    divisor := gcd(abs(num), den)
+    return Fraction{num: num / divisor, den: den / divisor}
+}
+
+func (f Fraction) String() string {
+    if f.den == 1 {
+        return strconv.Itoa(f.num)
+    }
+    return fmt.Sprintf("%d/%d", f.num, f.den)
+}
+
+// Splits the fraction multiplied by the factor into whole part (rounded down) and the remainder of the numerator,
+// which is zero for whole numbers.
+func (f Fraction) times(factor int) (whole, remainder int) {
+    product := f.num * factor
+    whole, remainder = product/f.den, product%f.den
+    if remainder < 0 {
+        whole, remainder = whole-1, remainder+f.den
+    }
+    return whole, remainder
+}
+
+// ParseFraction parses whole number ("-3") or fraction ("3/2").
+func ParseFraction(text string) (Fraction, error) {
+    numText, denText, isFraction := strings.Cut(text, "/")
+    num, err := strconv.Atoi(numText)
+    if err != nil {
+        return Fraction{}, fmt.Errorf("invalid number %q", numText)
+    }
+    den := 1
+    if isFraction {
+        if den, err = strconv.Atoi(denText); err != nil || den == 0 {
+            return Fraction{}, fmt.Errorf("invalid denominator %q", denText)
+        }
+    }
+    return NewFraction(num, den), nil
+}
+
+func gcd(a, b int) int {
+    for b != 0 {
+        a, b = b, a%b
+    }
+    if a == 0 {
+        return 1
+    }
+    return a
+}
+
+func abs(value int) int {
+    if value < 0 {
+        return -value
+    }
+    return value
+}
+
+// Slope of the ride: every step moves the toboggan down by given number of rows and right by given (possibly
+// negative or fractional) number of columns.
+type Slope struct {
+    Right Fraction
+    Down  int
+}
+
+// NewSlope returns the slope with whole number of columns to the right.
+func NewSlope(right, down int) Slope {
+    return Slope{Right: NewFraction(right, 1), Down: down}
+}
+
+func (s Slope) String() string {
+    return fmt.Sprintf("right %v, down %d", s.Right, s.Down)
+}
+
+// ParseSlope parses slope given as "right,down", e.g. "3,1", "-1,2" or "1/2,1". Down movement has to be positive,
+// otherwise the ride never ends.
+func ParseSlope(text string) (Slope, error) {
+    rightText, downText, ok := strings.Cut(strings.ReplaceAll(text, " ", ""), ",")
+    if !ok {
+        return Slope{}, fmt.Errorf("slope %q is not in format right,down", text)
+    }
+    right, err := ParseFraction(rightText)
+    if err != nil {
+        return Slope{}, fmt.Errorf("slope %q: %w", text, err)
+    }
+    down, err := strconv.Atoi(downText)
+    if err != nil || down < 1 {
+        return Slope{}, fmt.Errorf("slope %q: down movement has to be a positive number", text)
+    }
+    return Slope{Right: right, Down: down}, nil
+}
+
+// Rounding decides which square the toboggan passes when fractional slope gets it between two columns.
+type Rounding int
+
+const (
+    // Toboggan passes the square on the left (it is still above it).
+    RoundDown Rounding = iota
+    // Toboggan passes the nearer of the squares, the right one if both are equally near.
+    RoundNearest
+    // Toboggan flies over both squares and passes only rows where it is exactly on some column.
+    SkipBetween
+)
+
+// Starting square of every ride, the top left corner of the map.
+var start = grid.Point{}
+
+// Path returns positions of all squares passed on the ride from the start to the bottom of the map. Columns are not
+// wrapped, so they continue beyond the map width (or below zero for slopes going left).
+func (m *Map) Path(s Slope, rounding Rounding) []grid.Point {
+    var path []grid.Point
+    for step := 0; step*s.Down < m.squares.Height(); step++ {
+        column, remainder := s.Right.times(step)
+        if remainder != 0 {
+            if rounding == SkipBetween {
+                continue
+            }
+            if rounding == RoundNearest && 2*remainder >= s.Right.den {
+                column++
+            }
+        }
+        path = append(path, grid.Point{X: column, Y: step * s.Down})
+    }
+    return path
+}
+
+// CountTrees returns the number of trees encountered on the ride of the slope.
+func (m *Map) CountTrees(s Slope, rounding Rounding) int {
+    return m.countPathTrees(m.Path(s, rounding))
+}
+
+// Counts the trees of the path. The toboggan only encounters trees after it leaves the start, as in the puzzle.
+func (m *Map) countPathTrees(path []grid.Point) int {
+    trees := 0
+    for _, position := range path {
+        if square, _ := m.squares.Get(position); square == Tree && position != start {
+            trees++
+        }
+    }
+    return trees
+}
+
+// Evaluate returns the number of trees encountered on the ride for each of the slopes.
+func (m *Map) Evaluate(slopes []Slope, rounding Rounding) []int {
+    trees := make([]int, len(slopes))
+    for i, s := range slopes {
+        trees[i] = m.CountTrees(s, rounding)
+    }
+    return trees
+}
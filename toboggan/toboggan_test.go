@@ -0,0 +1,227 @@
+package toboggan
+
+import (
+    "bytes"
+    "errors"
+    "fmt"
+    "image/png"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+
+    "AdventOfCode20/input"
+)
+
+// Loads the example map of the puzzle.
+func loadExample(t *testing.T) *Map {
+    t.Helper()
+    file, err := os.Open(filepath.Join("..", "Day3", "example"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer file.Close()
+    m, err := Load(file, input.FailOnError)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return m
+}
+
+func TestEvaluateSlopes(t *testing.T) {
+    m := loadExample(t)
+    // Example map is 11 columns wide, so going 8 columns left is the same as going 3 columns right.
+    for text, expected := range map[string]int{
+        "1,1": 2, "3,1": 7, "5,1": 3, "7,1": 4, "1,2": 2, "-8,1": 7, "14,1": 7, "0,1": 3, "1/1,1": 2, "6/2,1": 7,
+    } {
+        s, err := ParseSlope(text)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if trees := m.Evaluate([]Slope{s}, RoundDown)[0]; trees != expected {
+            t.Errorf("%s: got %d trees, want %d", s, trees, expected)
+        }
+    }
+
+    // Tree on the start is not encountered on the ride.
+    corner, err := Load(strings.NewReader("#.\n.#\n"), input.FailOnError)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if trees := corner.CountTrees(NewSlope(1, 1), RoundDown); trees != 1 {
+        t.Errorf("got %d trees, want 1 without the start", trees)
+    }
+
+    for _, text := range []string{"3", "3,0", "3,-1", "1/0,1", "a,1", "1/x,1"} {
+        if _, err := ParseSlope(text); err == nil {
+            t.Errorf("%q: expected error", text)
+        }
+    }
+}
+
+func TestFractionalSlopes(t *testing.T) {
+    m := loadExample(t)
+    half, _ := ParseSlope("1/2,1")
+    // Skipping the rows between columns leaves exactly the ride going 1 right and 2 down.
+    trees, expected := m.CountTrees(half, SkipBetween), m.CountTrees(NewSlope(1, 2), RoundDown)
+    if trees != expected {
+        t.Errorf("skipping between columns: got %d trees, want %d", trees, expected)
+    }
+
+    for _, test := range []struct {
+        text     string
+        rounding Rounding
+        columns  []int
+    }{
+        {"1/2,1", RoundDown, []int{0, 0, 1, 1, 2, 2, 3, 3, 4, 4, 5}},
+        {"1/2,1", RoundNearest, []int{0, 1, 1, 2, 2, 3, 3, 4, 4, 5, 5}},
+        {"-1/3,2", RoundDown, []int{0, -1, -1, -1, -2, -2}},
+        {"-1/3,2", RoundNearest, []int{0, 0, -1, -1, -1, -2}},
+        {"5/3,3", SkipBetween, []int{0, 5}},
+    } {
+        s, err := ParseSlope(test.text)
+        if err != nil {
+            t.Fatal(err)
+        }
+        path := m.Path(s, test.rounding)
+        var columns []int
+        for i, position := range path {
+            columns = append(columns, position.X)
+            if test.rounding != SkipBetween && position.Y != i*s.Down {
+                t.Errorf("%s: got row %d on step %d", s, position.Y, i)
+            }
+        }
+        if fmt.Sprint(columns) != fmt.Sprint(test.columns) {
+            t.Errorf("%s (rounding %d): got columns %v, want %v", s, test.rounding, columns, test.columns)
+        }
+    }
+}
+
+func TestFindSlope(t *testing.T) {
+    m := loadExample(t)
+    bounds := Bounds{MinRight: -3, MaxRight: 7, MaxDown: 3, MaxDenominator: 1}
+
+    // Compare with shares of trees of every slope within the bounds.
+    var lowest, highest Result
+    for down := 1; down <= bounds.MaxDown; down++ {
+        for right := bounds.MinRight; right <= bounds.MaxRight; right++ {
+            s := NewSlope(right, down)
+            result := Result{Slope: s, Trees: m.CountTrees(s, RoundDown), Rows: len(m.Path(s, RoundDown))}
+            if lowest.Rows == 0 || compareShares(result, lowest) < 0 {
+                lowest = result
+            }
+            if highest.Rows == 0 || compareShares(result, highest) > 0 {
+                highest = result
+            }
+        }
+    }
+    for goal, expected := range map[Goal]Result{FewestTrees: lowest, MostTrees: highest} {
+        result, err := m.FindSlope(bounds, goal, RoundDown)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if compareShares(result, expected) != 0 || m.CountTrees(result.Slope, RoundDown) != result.Trees {
+            t.Errorf("goal %d: got %d trees of %d rows (%v), want share %d/%d", goal, result.Trees, result.Rows,
+                result.Slope, expected.Trees, expected.Rows)
+        }
+    }
+
+    // Slopes going 1 or 3 right and 3 down hit no tree on their 4 rows, but the longer ride without trees wins.
+    result, _ := m.FindSlope(bounds, FewestTrees, RoundDown)
+    if result.Slope != NewSlope(5, 2) || result.Trees != 0 || result.Rows != 6 {
+        t.Errorf("got %d trees of %d rows (%v), want no tree on 6 rows going right 5, down 2", result.Trees,
+            result.Rows, result.Slope)
+    }
+
+    // Fractions can only make the extremes better.
+    bounds.MaxDenominator = 4
+    if result, _ := m.FindSlope(bounds, MostTrees, RoundNearest); compareShares(result, highest) < 0 {
+        t.Errorf("got %d trees of %d rows with fractions, want share at least %d/%d", result.Trees, result.Rows,
+            highest.Trees, highest.Rows)
+    }
+    if _, err := m.FindSlope(Bounds{MinRight: 1, MaxRight: 0, MaxDown: 1, MaxDenominator: 1}, FewestTrees,
+        RoundDown); err == nil {
+        t.Error("expected error of empty bounds")
+    }
+}
+
+func TestWriteASCII(t *testing.T) {
+    m := loadExample(t)
+    var output bytes.Buffer
+    if err := m.WriteASCII(&output, []Ride{m.Ride(NewSlope(3, 1), RoundDown)}, false); err != nil {
+        t.Fatal(err)
+    }
+
+    // Drawing of the puzzle text, the map is repeated as far as the ride goes. The start is not marked.
+    expected := `..##.........##.........##.....
+#..O#...#..#...#...#..#...#...#
+.#....X..#..#....#..#..#....#..
+..#.#...#O#..#.#...#.#..#.#...#
+.#...##..#..X...##..#..#...##..
+..#.##.......#.X#.......#.##...
+.#.#.#....#.#.#.#.O..#.#.#.#...
+.#........#.#........X.#.......
+#.##...#...#.##...#...#.X#...#.
+#...##....##...##....##...#X...
+.#..#...#.#.#..#...#.#.#..#...X
+ride 1: right 3, down 1, 7 trees
+`
+    if output.String() != expected {
+        t.Errorf("got\n%s\nwant\n%s", output.String(), expected)
+    }
+
+    // Ride going left widens the map to the left, colors tell the rides apart.
+    output.Reset()
+    rides := []Ride{m.Ride(NewSlope(1, 1), RoundDown), m.Ride(NewSlope(-1, 2), RoundDown)}
+    if err := m.WriteASCII(&output, rides, true); err != nil {
+        t.Fatal(err)
+    }
+    lines := strings.Split(output.String(), "\n")
+    if row := lines[2]; !strings.HasPrefix(row, "#..#"+rideEscapes[1]+"O"+resetEscape) {
+        t.Errorf("got row %q, want mark of the second ride at column 4", row)
+    }
+}
+
+func TestWritePNG(t *testing.T) {
+    m := loadExample(t)
+    var output bytes.Buffer
+    rides := []Ride{m.Ride(NewSlope(3, 1), RoundDown), m.Ride(NewSlope(-1, 1), RoundDown)}
+    if err := m.WritePNG(&output, rides, 8); err != nil {
+        t.Fatal(err)
+    }
+    img, err := png.Decode(&output)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    // Map spans columns -10 to 30.
+    if size := img.Bounds().Size(); size.X != 41*8 || size.Y != 11*8 {
+        t.Errorf("got image %v, want 328x88", size)
+    }
+    // Center of the tree hit on row 2 (column 6) is crossed, the corner is not.
+    if c := img.At(16*8+4, 2*8+4); c != rideColors[0] {
+        t.Errorf("got color %v in the middle of X, want %v", c, rideColors[0])
+    }
+    if c := img.At(16*8+4, 2*8); c != treeColor {
+        t.Errorf("got color %v at the edge of X, want %v", c, treeColor)
+    }
+    // Open square passed by the second ride on row 1 (column -1) is outlined.
+    if c := img.At(9*8, 1*8+4); c != rideColors[1] {
+        t.Errorf("got color %v on the O outline, want %v", c, rideColors[1])
+    }
+}
+
+func TestImageTooLarge(t *testing.T) {
+    m := loadExample(t)
+    // Ride going 1000 right spans 10001 columns, the image would take gigabytes.
+    rides := []Ride{m.Ride(NewSlope(1000, 1), RoundDown)}
+    if _, err := m.drawImage(rides, 100); !errors.Is(err, ErrImageTooLarge) {
+        t.Errorf("got error %v, want %v", err, ErrImageTooLarge)
+    }
+    if _, err := m.drawImage(rides, MaxImagePixels+1); !errors.Is(err, ErrImageTooLarge) {
+        t.Errorf("got error %v of huge squares, want %v", err, ErrImageTooLarge)
+    }
+    if img, err := m.drawImage(rides, minSquareSize); err != nil || img.Bounds().Dx() != 10001*minSquareSize {
+        t.Errorf("got error %v, want image of 10001 columns", err)
+    }
+}
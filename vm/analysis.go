@@ -0,0 +1,181 @@
+package vm
+
+import "sort"
+
+// BlockEffect is the basic block together with its folded effect on the accumulator.
+type BlockEffect struct {
+    Block
+    // Accumulator is the total change of the accumulator made by the whole block. It is only valid if the block is
+    // Constant, i.e. all its instructions change the accumulator by a constant.
+    Accumulator int
+    Constant    bool
+}
+
+// Analysis is the result of static analysis of the program: facts found without executing it.
+type Analysis struct {
+    Graph *Graph
+    // Reachable marks the instructions which can be executed when the program starts on its first instruction.
+    Reachable []bool
+    // Unreachable lists indexes of instructions which can never be executed.
+    Unreachable []int
+    // OutOfRange lists indexes of instructions which jump outside of the program (and not to its end).
+    OutOfRange []int
+    // Loops lists guaranteed infinite loops: cycles of instructions which can never be left once entered. Every loop
+    // is a sorted list of instruction indexes.
+    Loops [][]int
+    // Terminates tells whether the end of the program is reachable from its start. For the original handheld console
+    // it means that the program certainly terminates.
+    Terminates bool
+    Blocks     []BlockEffect
+}
+
+// Analyze examines the control flow of the program and folds the accumulator changes of its basic blocks.
+func Analyze(program *Program) *Analysis {
+    graph := BuildGraph(program)
+    a := &Analysis{
+        Graph:     graph,
+        Reachable: make([]bool, graph.End()+1),
+    }
+
+    a.Reachable[0] = true
+    queue := []int{0}
+    for len(queue) > 0 {
+        node := queue[0]
+        queue = queue[1:]
+        for _, next := range graph.Successors(node) {
+            if graph.Contains(next) && !a.Reachable[next] {
+                a.Reachable[next] = true
+                queue = append(queue, next)
+            }
+        }
+    }
+    a.Terminates = a.Reachable[graph.End()]
+
+    for i := 0; i < program.Len(); i++ {
+        if !a.Reachable[i] {
+            a.Unreachable = append(a.Unreachable, i)
+        }
+        for _, next := range graph.Successors(i) {
+            if !graph.Contains(next) {
+                a.OutOfRange = append(a.OutOfRange, i)
+                break
+            }
+        }
+    }
+
+    a.Loops = graph.traps()
+
+    for _, block := range graph.Blocks() {
+        effect := BlockEffect{Block: block, Constant: true}
+        for i := block.Start; i < block.End; i++ {
+            inst := program.Instructions[i]
+            if inst.Op.AccumulatorDelta == nil {
+                effect.Constant = false
+                break
+            }
+            effect.Accumulator += inst.Op.AccumulatorDelta(inst.Argument)
+        }
+        a.Blocks = append(a.Blocks, effect)
+    }
+    return a
+}
+
+// InLoop returns the guaranteed infinite loop containing the instruction, nil if there is none.
+func (a *Analysis) InLoop(index int) []int {
+    for _, loop := range a.Loops {
+        i := sort.SearchInts(loop, index)
+        if i < len(loop) && loop[i] == index {
+            return loop
+        }
+    }
+    return nil
+}
+
+// Finds cycles which cannot be left: strongly connected components (with at least one edge) without any edge leading
+// out of them. Components are found by Tarjan's algorithm.
+func (g *Graph) traps() [][]int {
+    n := g.End()
+    order := make([]int, n)
+    lowLink := make([]int, n)
+    onStack := make([]bool, n)
+    var stack []int
+    var traps [][]int
+    counter := 0
+
+    var visit func(node int)
+    visit = func(node int) {
+        counter++
+        order[node] = counter
+        lowLink[node] = counter
+        stack = append(stack, node)
+        onStack[node] = true
+
+        for _, next := range g.successors[node] {
+            if next < 0 || next >= n {
+                continue
+            }
+            if order[next] == 0 {
+                visit(next)
+                lowLink[node] = min(lowLink[node], lowLink[next])
+            } else if onStack[next] {
+                lowLink[node] = min(lowLink[node], order[next])
+            }
+        }
+
+        if lowLink[node] != order[node] {
+            return
+        }
+        var component []int
+        for {
+            top := stack[len(stack)-1]
+            stack = stack[:len(stack)-1]
+            onStack[top] = false
+            component = append(component, top)
+            if top == node {
+                break
+            }
+        }
+        if g.isTrap(component) {
+            sort.Ints(component)
+            traps = append(traps, component)
+        }
+    }
+
+    for node := 0; node < n; node++ {
+        if order[node] == 0 {
+            visit(node)
+        }
+    }
+    sort.Slice(traps, func(i, j int) bool {
+        return traps[i][0] < traps[j][0]
+    })
+    return traps
+}
+
+// Decides whether the strongly connected component is a cycle which all edges stay in.
+func (g *Graph) isTrap(component []int) bool {
+    members := make(map[int]bool)
+    for _, node := range component {
+        members[node] = true
+    }
+
+    cyclic := len(component) > 1
+    for _, node := range component {
+        for _, next := range g.successors[node] {
+            if !members[next] {
+                return false
+            }
+            if next == node {
+                cyclic = true
+            }
+        }
+    }
+    return cyclic
+}
+
+func min(a, b int) int {
+    if a < b {
+        return a
+    }
+    return b
+}
@@ -0,0 +1,127 @@
+package vm
+
+import (
+    "bufio"
+    "fmt"
+    "io"
+    "strings"
+)
+
+// Label of the instruction on given index, used instead of relative offsets in listings.
+func label(index int) string {
+    return fmt.Sprintf("L%d", index)
+}
+
+// Labels returns names of all instructions targeted by relative jumps, indexed by their position. Jumps to the end
+// of the program target the "end" label.
+func (a *Analysis) Labels() map[int]string {
+    labels := make(map[int]string)
+    for i, inst := range a.Graph.program.Instructions {
+        if !inst.Op.Relative {
+            continue
+        }
+        target := i + inst.Argument
+        switch {
+        case target == a.Graph.End():
+            labels[target] = "end"
+        case a.Graph.Contains(target):
+            labels[target] = label(target)
+        }
+    }
+    return labels
+}
+
+// Disassemble returns the text of the instruction with its relative offset replaced by label of the target.
+// Offsets leaving the program are kept as they are.
+func (a *Analysis) Disassemble(index int, labels map[int]string) string {
+    inst := a.Graph.program.Instructions[index]
+    if name, ok := labels[index+inst.Argument]; ok && inst.Op.Relative {
+        return fmt.Sprintf("%s %s", inst.Op.Name, name)
+    }
+    return inst.String()
+}
+
+// WriteListing writes the pretty-printed program: instructions grouped to basic blocks with folded accumulator
+// changes, jumps to labels instead of raw offsets and problems found by the analysis as comments.
+func (a *Analysis) WriteListing(w io.Writer) error {
+    bw := bufio.NewWriter(w)
+    labels := a.Labels()
+    program := a.Graph.program
+
+    for _, block := range a.Blocks {
+        var notes []string
+        if block.Constant {
+            notes = append(notes, fmt.Sprintf("acc %+d", block.Accumulator))
+        } else {
+            notes = append(notes, "acc unknown")
+        }
+        if !a.Reachable[block.Start] {
+            notes = append(notes, "unreachable")
+        }
+        if a.InLoop(block.Start) != nil {
+            notes = append(notes, "infinite loop")
+        }
+        fmt.Fprintf(bw, "; block %d-%d: %s\n", block.Start, block.Last(), strings.Join(notes, ", "))
+
+        for i := block.Start; i < block.End; i++ {
+            prefix := ""
+            if name, ok := labels[i]; ok {
+                prefix = name + ":"
+            }
+            line := fmt.Sprintf("%-8s%-12s", prefix, a.Disassemble(i, labels))
+            for _, next := range a.Graph.Successors(i) {
+                if !a.Graph.Contains(next) {
+                    line += fmt.Sprintf("  ; out of range (%d)", next)
+                    break
+                }
+            }
+            fmt.Fprintln(bw, strings.TrimRight(line, " "))
+        }
+    }
+    if _, ok := labels[program.Len()]; ok {
+        fmt.Fprintln(bw, "end:")
+    }
+    return bw.Flush()
+}
+
+// WriteSummary writes the problems found by the analysis, one per line.
+func (a *Analysis) WriteSummary(w io.Writer) error {
+    bw := bufio.NewWriter(w)
+    if a.Terminates {
+        fmt.Fprintln(bw, "program can terminate")
+    } else {
+        fmt.Fprintln(bw, "program never terminates")
+    }
+    if len(a.Unreachable) > 0 {
+        fmt.Fprintf(bw, "%d unreachable instruction(s): %s\n", len(a.Unreachable), formatRanges(a.Unreachable))
+    }
+    for _, index := range a.OutOfRange {
+        fmt.Fprintf(bw, "jump out of range: %d: %v\n", index, a.Graph.program.Instructions[index])
+    }
+    for _, loop := range a.Loops {
+        entered := ""
+        if a.Reachable[loop[0]] {
+            entered = " (entered from start)"
+        }
+        fmt.Fprintf(bw, "guaranteed infinite loop: %s%s\n", formatRanges(loop), entered)
+    }
+    return bw.Flush()
+}
+
+// Formats sorted indexes as comma separated ranges ("1-3,7").
+func formatRanges(indexes []int) string {
+    var parts []string
+    for i := 0; i < len(indexes); {
+        j := i
+        for j+1 < len(indexes) && indexes[j+1] == indexes[j]+1 {
+            j++
+        }
+        if i == j {
+            parts = append(parts, fmt.Sprint(indexes[i]))
+        } else {
+            parts = append(parts, fmt.Sprintf("%d-%d", indexes[i], indexes[j]))
+        }
+        i = j + 1
+    }
+    return strings.Join(parts, ",")
+}
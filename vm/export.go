@@ -0,0 +1,253 @@
+package vm
+
+import (
+    "bufio"
+    "errors"
+    "fmt"
+    "io"
+    "strings"
+)
+
+// Transition is the move of the pointer from one instruction to another during the execution.
+type Transition struct {
+    From int
+    To   int
+}
+
+// Highlight marks the parts of the control-flow graph which were visited by a run of the program.
+type Highlight struct {
+    // Executed holds every transition made by the run.
+    Executed map[Transition]bool
+    // Loop holds transitions of the loop the run ended in, it is empty if the run did not end in a loop.
+    Loop map[Transition]bool
+}
+
+// HighlightRun collects the transitions from the trace of the stopped machine, so tracing has to be enabled.
+func HighlightRun(m *Machine) (*Highlight, error) {
+    if !m.config.Trace {
+        return nil, errors.New("highlighting requires machine with enabled trace")
+    }
+
+    h := &Highlight{Executed: make(map[Transition]bool), Loop: make(map[Transition]bool)}
+    loopStart := -1
+    for i, entry := range m.trace {
+        h.Executed[Transition{From: entry.Index, To: entry.After.Pointer}] = true
+        if m.exitCode == InfiniteLoop && loopStart == -1 && entry.Before == m.registers {
+            loopStart = i
+        }
+    }
+    // Revisit detection stops on the same instruction, but not necessarily in the same state.
+    if m.exitCode == InfiniteLoop && loopStart == -1 {
+        for i, entry := range m.trace {
+            if entry.Index == m.registers.Pointer {
+                loopStart = i
+                break
+            }
+        }
+    }
+    if loopStart != -1 {
+        for _, entry := range m.trace[loopStart:] {
+            h.Loop[Transition{From: entry.Index, To: entry.After.Pointer}] = true
+        }
+    }
+    return h, nil
+}
+
+// Decides whether any transition of the set leaves the instruction inside the block.
+func leavesBlock(transitions map[Transition]bool, block Block) bool {
+    for t := range transitions {
+        if t.From >= block.Start && t.From < block.End {
+            return true
+        }
+    }
+    return false
+}
+
+// Edge of the graph of basic blocks.
+type blockEdge struct {
+    from        string
+    to          string
+    label       string
+    fallThrough bool
+    executed    bool
+    loop        bool
+}
+
+// Node of the graph of basic blocks.
+type blockNode struct {
+    id       string
+    lines    []string
+    executed bool
+    loop     bool
+}
+
+// Converts the graph into basic blocks with edges between them. Termination is the special "exit" node ("end" is a
+// keyword of Mermaid), edges leaving the program lead to the special "out" node and are labelled by their target.
+func (g *Graph) blockGraph(h *Highlight) ([]blockNode, []blockEdge) {
+    if h == nil {
+        h = &Highlight{}
+    }
+
+    var nodes []blockNode
+    var edges []blockEdge
+    out := false
+    for _, block := range g.Blocks() {
+        node := blockNode{
+            id:       blockID(block.Start),
+            executed: leavesBlock(h.Executed, block),
+            loop:     leavesBlock(h.Loop, block),
+        }
+        for i := block.Start; i < block.End; i++ {
+            node.lines = append(node.lines, fmt.Sprintf("%d: %v", i, g.program.Instructions[i]))
+        }
+        nodes = append(nodes, node)
+
+        last := block.Last()
+        for _, next := range g.successors[last] {
+            edge := blockEdge{
+                from:        node.id,
+                fallThrough: next == last+1,
+                executed:    h.Executed[Transition{From: last, To: next}],
+                loop:        h.Loop[Transition{From: last, To: next}],
+            }
+            switch {
+            case next == g.End():
+                edge.to = "exit"
+            case g.Contains(next):
+                edge.to = blockID(next)
+            default:
+                edge.to = "out"
+                edge.label = fmt.Sprint(next)
+                out = true
+            }
+            edges = append(edges, edge)
+        }
+    }
+
+    exit := blockNode{id: "exit", lines: []string{"end"}}
+    for _, edge := range edges {
+        if edge.to == "exit" && edge.executed {
+            exit.executed = true
+        }
+    }
+    nodes = append(nodes, exit)
+    if out {
+        nodes = append(nodes, blockNode{id: "out", lines: []string{"out of range"}})
+    }
+    return nodes, edges
+}
+
+// Escapes the text for quoted string of DOT language, line breaks are replaced by given escape sequence.
+func escapeDOT(text string, lineBreak string) string {
+    return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", lineBreak).Replace(text)
+}
+
+func blockID(start int) string {
+    return fmt.Sprintf("b%d", start)
+}
+
+// WriteDOT writes the graph of basic blocks in Graphviz DOT language. Jumps are drawn as solid edges, fall-throughs as
+// dashed ones. The optional highlight colours the executed path blue and the loop red.
+func (g *Graph) WriteDOT(w io.Writer, h *Highlight) error {
+    nodes, edges := g.blockGraph(h)
+    bw := bufio.NewWriter(w)
+
+    fmt.Fprintln(bw, "digraph program {")
+    fmt.Fprintln(bw, "    node [shape=box, fontname=monospace];")
+    for _, node := range nodes {
+        var attributes []string
+        // Lines of blocks are left-justified by "\l" escapes.
+        label := escapeDOT(strings.Join(node.lines, "\n")+"\n", `\l`)
+        if node.id == "exit" || node.id == "out" {
+            label = escapeDOT(node.lines[0], "")
+            attributes = append(attributes, "shape=oval")
+        }
+        attributes = append(attributes, fmt.Sprintf(`label="%s"`, label))
+        switch {
+        case node.loop:
+            attributes = append(attributes, "style=filled", "fillcolor=lightcoral")
+        case node.executed:
+            attributes = append(attributes, "style=filled", "fillcolor=lightblue")
+        }
+        fmt.Fprintf(bw, "    %s [%s];\n", node.id, strings.Join(attributes, ", "))
+    }
+    for _, edge := range edges {
+        var attributes []string
+        if edge.fallThrough {
+            attributes = append(attributes, "style=dashed")
+        }
+        if edge.label != "" {
+            attributes = append(attributes, fmt.Sprintf("label=%q", edge.label))
+        }
+        switch {
+        case edge.loop:
+            attributes = append(attributes, "color=red", "penwidth=2")
+        case edge.executed:
+            attributes = append(attributes, "color=blue", "penwidth=2")
+        }
+
+        fmt.Fprintf(bw, "    %s -> %s", edge.from, edge.to)
+        if len(attributes) > 0 {
+            fmt.Fprintf(bw, " [%s]", strings.Join(attributes, ", "))
+        }
+        fmt.Fprintln(bw, ";")
+    }
+    fmt.Fprintln(bw, "}")
+    return bw.Flush()
+}
+
+// WriteMermaid writes the graph of basic blocks as Mermaid flowchart. Jumps are drawn as solid edges, fall-throughs as
+// dotted ones. The optional highlight colours the executed path blue and the loop red.
+func (g *Graph) WriteMermaid(w io.Writer, h *Highlight) error {
+    nodes, edges := g.blockGraph(h)
+    bw := bufio.NewWriter(w)
+
+    fmt.Fprintln(bw, "flowchart TD")
+    classes := make(map[string][]string)
+    for _, node := range nodes {
+        if node.id == "exit" || node.id == "out" {
+            fmt.Fprintf(bw, "    %s([\"%s\"])\n", node.id, node.lines[0])
+        } else {
+            fmt.Fprintf(bw, "    %s[\"%s\"]\n", node.id, strings.Join(node.lines, "<br/>"))
+        }
+        switch {
+        case node.loop:
+            classes["loop"] = append(classes["loop"], node.id)
+        case node.executed:
+            classes["executed"] = append(classes["executed"], node.id)
+        }
+    }
+
+    styles := make(map[string][]string)
+    for i, edge := range edges {
+        arrow := "-->"
+        if edge.fallThrough {
+            arrow = "-.->"
+        }
+        if edge.label != "" {
+            arrow += "|" + edge.label + "|"
+        }
+        fmt.Fprintf(bw, "    %s %s %s\n", edge.from, arrow, edge.to)
+
+        switch {
+        case edge.loop:
+            styles["loop"] = append(styles["loop"], fmt.Sprint(i))
+        case edge.executed:
+            styles["executed"] = append(styles["executed"], fmt.Sprint(i))
+        }
+    }
+
+    colors := map[string]string{"loop": "lightcoral", "executed": "lightblue"}
+    strokes := map[string]string{"loop": "red", "executed": "blue"}
+    for _, name := range []string{"executed", "loop"} {
+        if len(classes[name]) > 0 {
+            fmt.Fprintf(bw, "    classDef %s fill:%s\n", name, colors[name])
+            fmt.Fprintf(bw, "    class %s %s\n", strings.Join(classes[name], ","), name)
+        }
+        if len(styles[name]) > 0 {
+            fmt.Fprintf(bw, "    linkStyle %s stroke:%s,stroke-width:2px\n", strings.Join(styles[name], ","),
+                strokes[name])
+        }
+    }
+    return bw.Flush()
+}
@@ -0,0 +1,127 @@
+package vm
+
+// Graph is the control-flow graph of the program. Its nodes are indexes of instructions plus one extra node (End)
+// representing the successful termination. Edges lead from every instruction to all instructions which can be
+// executed right after it; edges leaving the program (and not to its end) are kept only as successors.
+type Graph struct {
+    program      *Program
+    successors   [][]int
+    predecessors [][]int
+}
+
+// BuildGraph analyses the control flow of the program without executing it.
+func BuildGraph(program *Program) *Graph {
+    n := program.Len()
+    g := &Graph{
+        program:      program,
+        successors:   make([][]int, n),
+        predecessors: make([][]int, n+1),
+    }
+    for i, inst := range program.Instructions {
+        g.successors[i] = inst.Successors(i)
+        for _, next := range g.successors[i] {
+            if g.Contains(next) {
+                g.predecessors[next] = append(g.predecessors[next], i)
+            }
+        }
+    }
+    return g
+}
+
+// Program returns the program the graph was built of.
+func (g *Graph) Program() *Program {
+    return g.program
+}
+
+// End returns the node of successful termination.
+func (g *Graph) End() int {
+    return len(g.successors)
+}
+
+// Contains decides whether the index is a node of the graph (an instruction or the end).
+func (g *Graph) Contains(index int) bool {
+    return index >= 0 && index <= g.End()
+}
+
+// Successors returns indexes of instructions which can follow the instruction on given index. Some of them might lay
+// outside of the graph. The end node has no successors.
+func (g *Graph) Successors(index int) []int {
+    if index == g.End() {
+        return nil
+    }
+    return g.successors[index]
+}
+
+// Predecessors returns indexes of instructions which can be executed right before the node on given index.
+func (g *Graph) Predecessors(index int) []int {
+    return g.predecessors[index]
+}
+
+// Terminating marks every node from which the end of the program can be reached. For the original handheld console,
+// whose instructions always have a single successor, the program terminates if and only if it starts on the marked
+// instruction.
+func (g *Graph) Terminating() []bool {
+    terminating := make([]bool, g.End()+1)
+    terminating[g.End()] = true
+    queue := []int{g.End()}
+    for len(queue) > 0 {
+        node := queue[0]
+        queue = queue[1:]
+        for _, previous := range g.predecessors[node] {
+            if !terminating[previous] {
+                terminating[previous] = true
+                queue = append(queue, previous)
+            }
+        }
+    }
+    return terminating
+}
+
+// Block is a basic block of the program: a maximal sequence of instructions which is only entered by its first
+// instruction and only left by its last one. Instructions are indexed from Start up to End (exclusive).
+type Block struct {
+    Start int
+    End   int
+}
+
+// Last returns the index of the last instruction of the block.
+func (b Block) Last() int {
+    return b.End - 1
+}
+
+// Blocks splits the program into basic blocks, in order of their instructions. A block starts on the first
+// instruction, on every target of a jump and right after every instruction which does not simply continue with the
+// next one.
+func (g *Graph) Blocks() []Block {
+    n := g.End()
+    leaders := make([]bool, n+1)
+    leaders[0] = true
+    leaders[n] = true
+    for i := 0; i < n; i++ {
+        if g.fallsThrough(i) {
+            continue
+        }
+        leaders[i+1] = true
+        for _, next := range g.successors[i] {
+            if g.Contains(next) {
+                leaders[next] = true
+            }
+        }
+    }
+
+    var blocks []Block
+    start := 0
+    for i := 1; i <= n; i++ {
+        if leaders[i] {
+            blocks = append(blocks, Block{Start: start, End: i})
+            start = i
+        }
+    }
+    return blocks
+}
+
+// Decides whether the instruction always continues with the next one.
+func (g *Graph) fallsThrough(index int) bool {
+    successors := g.successors[index]
+    return len(successors) == 1 && successors[0] == index+1
+}
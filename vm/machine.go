@@ -0,0 +1,264 @@
+package vm
+
+import (
+    "context"
+    "fmt"
+)
+
+// ExitCode describes the state of the machine after the last executed instruction.
+type ExitCode int
+
+const (
+    // Running machine has an instruction to execute next.
+    Running ExitCode = iota
+    // Success means the pointer moved right after the last instruction of the program.
+    Success
+    // InfiniteLoop means the machine would enter the loop by executing the next instruction.
+    InfiniteLoop
+    // OutOfRange means the pointer moved outside of the program (and not to its end).
+    OutOfRange
+    // StepLimitExceeded means the machine executed the maximal number of steps allowed by the config.
+    StepLimitExceeded
+    // Cancelled means the run was stopped by its context.
+    Cancelled
+)
+
+func (c ExitCode) String() string {
+    switch c {
+    case Running:
+        return "running"
+    case Success:
+        return "success"
+    case InfiniteLoop:
+        return "infinite loop"
+    case OutOfRange:
+        return "out of range"
+    case StepLimitExceeded:
+        return "step limit exceeded"
+    case Cancelled:
+        return "cancelled"
+    default:
+        return fmt.Sprintf("exit code %d", int(c))
+    }
+}
+
+// LoopDetection decides how the machine recognizes that the program will never terminate.
+type LoopDetection int
+
+const (
+    // DetectRevisit stops the machine before any instruction is executed for the second time. It is only correct for
+    // programs whose control flow does not depend on the accumulator, which holds for the original handheld console.
+    DetectRevisit LoopDetection = iota
+    // DetectRepeatedState stops the machine once it returns to already seen registers. It works for any opcodes, but
+    // it has to remember every visited state.
+    DetectRepeatedState
+    // DetectNone never stops the machine because of a loop.
+    DetectNone
+)
+
+// Config holds optional settings of the machine, zero value is the behaviour of the original handheld console.
+type Config struct {
+    LoopDetection LoopDetection
+    // Trace enables recording of every executed instruction.
+    Trace bool
+    // Patch replaces some instructions for this machine only, the shared program stays intact.
+    Patch Patch
+    // MaxSteps stops the machine after given number of executed instructions, zero means no limit.
+    MaxSteps int
+}
+
+// TraceEntry records the single executed instruction with registers before and after its execution.
+type TraceEntry struct {
+    Step        int
+    Index       int
+    Instruction Instruction
+    Before      Registers
+    After       Registers
+}
+
+func (e TraceEntry) String() string {
+    return fmt.Sprintf("%6d  %4d: %-10v acc %d -> %d", e.Step, e.Index, e.Instruction, e.Before.Accumulator,
+        e.After.Accumulator)
+}
+
+// Machine executes the program instruction by instruction. The program itself is never modified; all the execution
+// state (registers, execution counts, trace) is held by the machine. Many machines can therefore run the same program
+// concurrently, each of them in its own goroutine.
+type Machine struct {
+    program    *Program
+    config     Config
+    registers  Registers
+    execCounts []int
+    seen       map[Registers]bool
+    steps      int
+    exitCode   ExitCode
+    trace      []TraceEntry
+}
+
+// New creates machine ready to execute the program from its first instruction.
+func New(program *Program, config Config) *Machine {
+    m := &Machine{program: program, config: config}
+    m.Reset()
+    return m
+}
+
+// Reset sets the machine to its initial (zeroed) state.
+func (m *Machine) Reset() {
+    m.registers = Registers{}
+    m.execCounts = make([]int, m.program.Len())
+    m.seen = nil
+    if m.config.LoopDetection == DetectRepeatedState {
+        m.seen = make(map[Registers]bool)
+    }
+    m.steps = 0
+    m.trace = nil
+    m.exitCode = m.check()
+}
+
+// Step executes the next instruction and returns the state of the machine afterwards. Once the machine stops, further
+// steps do nothing. Only cancelled machine continues, as the cancellation is not caused by the program.
+func (m *Machine) Step() ExitCode {
+    if m.exitCode == Cancelled {
+        m.exitCode = m.check()
+    }
+    if m.exitCode != Running {
+        return m.exitCode
+    }
+
+    index := m.registers.Pointer
+    inst := m.Instruction(index)
+    before := m.registers
+    if m.seen != nil {
+        m.seen[before] = true
+    }
+
+    inst.Op.Execute(&m.registers, inst.Argument)
+    m.execCounts[index]++
+    m.steps++
+    if m.config.Trace {
+        m.trace = append(m.trace, TraceEntry{
+            Step:        m.steps,
+            Index:       index,
+            Instruction: inst,
+            Before:      before,
+            After:       m.registers,
+        })
+    }
+
+    m.exitCode = m.check()
+    return m.exitCode
+}
+
+// StepBack reverts the last executed instruction using the recorded trace, so it only works when tracing is enabled.
+// The flag tells whether there was any instruction to revert.
+func (m *Machine) StepBack() bool {
+    if len(m.trace) == 0 {
+        return false
+    }
+
+    last := m.trace[len(m.trace)-1]
+    m.trace = m.trace[:len(m.trace)-1]
+    m.registers = last.Before
+    m.execCounts[last.Index]--
+    m.steps--
+    if m.seen != nil {
+        // The state was seen for the first time, otherwise the machine would have stopped before it.
+        delete(m.seen, last.Before)
+    }
+    m.exitCode = m.check()
+    return true
+}
+
+// Run executes the program until the machine stops.
+func (m *Machine) Run() ExitCode {
+    for m.Step() == Running {
+    }
+    return m.exitCode
+}
+
+// How many steps are executed between checks of the context.
+const cancelCheckInterval = 1024
+
+// RunContext executes the program until the machine stops or the context is done. Cancelled machine can continue by
+// another run (or steps).
+func (m *Machine) RunContext(ctx context.Context) ExitCode {
+    for i := 0; ; i++ {
+        if i%cancelCheckInterval == 0 && ctx.Err() != nil {
+            m.exitCode = Cancelled
+            return m.exitCode
+        }
+        if m.Step() != Running {
+            return m.exitCode
+        }
+    }
+}
+
+// Decides whether the machine can continue with the instruction its pointer points to.
+func (m *Machine) check() ExitCode {
+    pointer := m.registers.Pointer
+    switch {
+    case pointer == m.program.Len():
+        return Success
+    case pointer < 0 || pointer > m.program.Len():
+        return OutOfRange
+    }
+
+    switch m.config.LoopDetection {
+    case DetectRevisit:
+        if m.execCounts[pointer] > 0 {
+            return InfiniteLoop
+        }
+    case DetectRepeatedState:
+        if m.seen[m.registers] {
+            return InfiniteLoop
+        }
+    }
+
+    if m.config.MaxSteps > 0 && m.steps >= m.config.MaxSteps {
+        return StepLimitExceeded
+    }
+    return Running
+}
+
+// Program returns the program executed by the machine.
+func (m *Machine) Program() *Program {
+    return m.program
+}
+
+// Instruction returns the instruction on given index as the machine executes it, i.e. with the patch applied.
+func (m *Machine) Instruction(index int) Instruction {
+    if inst, ok := m.config.Patch[index]; ok {
+        return inst
+    }
+    return m.program.Instructions[index]
+}
+
+// Registers returns the current values of registers.
+func (m *Machine) Registers() Registers {
+    return m.registers
+}
+
+// Accumulator returns the current value of the accumulator.
+func (m *Machine) Accumulator() int {
+    return m.registers.Accumulator
+}
+
+// ExitCode returns the state of the machine after the last executed instruction.
+func (m *Machine) ExitCode() ExitCode {
+    return m.exitCode
+}
+
+// Steps returns the number of instructions executed since the last reset.
+func (m *Machine) Steps() int {
+    return m.steps
+}
+
+// ExecCount returns how many times the instruction on given index was executed since the last reset.
+func (m *Machine) ExecCount(index int) int {
+    return m.execCounts[index]
+}
+
+// Trace returns all executed instructions in order of their execution. It is empty unless enabled in the config.
+func (m *Machine) Trace() []TraceEntry {
+    return m.trace
+}
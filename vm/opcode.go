@@ -0,0 +1,126 @@
+// Package vm implements the handheld game console from Day 8: a tiny virtual machine with a single accumulator,
+// relative jumps and an extensible table of opcodes. Besides plain execution it provides an assembler, step-wise
+// execution with configurable loop detection and execution traces.
+package vm
+
+import (
+    "fmt"
+    "sort"
+)
+
+// Registers hold the state of the machine which instructions work with.
+type Registers struct {
+    Accumulator int
+    Pointer     int
+}
+
+// Opcode defines the behaviour of one kind of instruction.
+type Opcode struct {
+    Name string
+    // Execute changes the registers according to the instruction argument, including the move of the pointer.
+    Execute func(r *Registers, argument int)
+    // Successors lists the indexes of all instructions which can be executed after the instruction on given index.
+    // It describes the control flow statically (without running the program).
+    Successors func(index, argument int) []int
+    // Relative marks opcodes whose argument is an offset of another instruction (and not a plain value).
+    Relative bool
+    // AccumulatorDelta returns the constant change of the accumulator made by the instruction. It is nil if the
+    // change depends on the state of the machine.
+    AccumulatorDelta func(argument int) int
+}
+
+// FallThrough is the control flow of instructions always followed by the next one.
+func FallThrough(index, argument int) []int {
+    return []int{index + 1}
+}
+
+// RelativeJump is the control flow of instructions jumping by their argument.
+func RelativeJump(index, argument int) []int {
+    return []int{index + argument}
+}
+
+// Opcodes of the original handheld console.
+var (
+    Accumulate = &Opcode{
+        Name: "acc",
+        Execute: func(r *Registers, argument int) {
+            r.Accumulator += argument
+            r.Pointer++
+        },
+        Successors: FallThrough,
+        AccumulatorDelta: func(argument int) int {
+            return argument
+        },
+    }
+    Jump = &Opcode{
+        Name: "jmp",
+        Execute: func(r *Registers, argument int) {
+            r.Pointer += argument
+        },
+        Successors:       RelativeJump,
+        Relative:         true,
+        AccumulatorDelta: noChange,
+    }
+    NoOperation = &Opcode{
+        Name: "nop",
+        Execute: func(r *Registers, argument int) {
+            r.Pointer++
+        },
+        Successors:       FallThrough,
+        AccumulatorDelta: noChange,
+    }
+)
+
+func noChange(argument int) int {
+    return 0
+}
+
+// InstructionSet is a table of opcodes which programs can use, indexed by their names.
+type InstructionSet struct {
+    opcodes map[string]*Opcode
+}
+
+// NewInstructionSet creates instruction set of given opcodes.
+func NewInstructionSet(opcodes ...*Opcode) *InstructionSet {
+    set := &InstructionSet{opcodes: make(map[string]*Opcode)}
+    for _, opcode := range opcodes {
+        if err := set.Define(opcode); err != nil {
+            panic(err)
+        }
+    }
+    return set
+}
+
+// Handheld returns a new instruction set of the original handheld console (acc, jmp and nop), which can be further
+// extended.
+func Handheld() *InstructionSet {
+    return NewInstructionSet(Accumulate, Jump, NoOperation)
+}
+
+// Define adds the opcode to the instruction set. Names of opcodes have to be unique within the set.
+func (s *InstructionSet) Define(opcode *Opcode) error {
+    if opcode.Name == "" || opcode.Execute == nil || opcode.Successors == nil {
+        return fmt.Errorf("opcode %q is not fully defined", opcode.Name)
+    }
+    if _, ok := s.opcodes[opcode.Name]; ok {
+        return fmt.Errorf("opcode %q is already defined", opcode.Name)
+    }
+    s.opcodes[opcode.Name] = opcode
+    return nil
+}
+
+// Lookup returns the opcode of given name together with flag whether the set contains it.
+func (s *InstructionSet) Lookup(name string) (*Opcode, bool) {
+    opcode, ok := s.opcodes[name]
+    return opcode, ok
+}
+
+// Names returns names of all opcodes of the set in alphabetical order.
+func (s *InstructionSet) Names() []string {
+    var names []string
+    for name := range s.opcodes {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    return names
+}
@@ -0,0 +1,90 @@
+package vm
+
+import (
+    "context"
+    "sort"
+    "sync"
+)
+
+// Patch replaces instructions of the program on given indexes. It is applied by the machine during execution, so
+// patched runs never modify the program they share.
+type Patch map[int]Instruction
+
+// Apply creates a copy of the program with the patch applied.
+func (p Patch) Apply(program *Program) *Program {
+    patched := program.Clone()
+    for index, inst := range p {
+        patched.Instructions[index] = inst
+    }
+    return patched
+}
+
+// Indexes returns the patched indexes in increasing order.
+func (p Patch) Indexes() []int {
+    var indexes []int
+    for index := range p {
+        indexes = append(indexes, index)
+    }
+    sort.Ints(indexes)
+    return indexes
+}
+
+// SingleSubstitutions creates a patch for every allowed substitution of every single instruction, in order of the
+// instructions.
+func SingleSubstitutions(program *Program, substitutions []Substitution) []Patch {
+    var patches []Patch
+    for index, inst := range program.Instructions {
+        for _, s := range substitutions {
+            if inst.Op == s.From {
+                patches = append(patches, Patch{index: {Op: s.To, Argument: inst.Argument}})
+            }
+        }
+    }
+    return patches
+}
+
+// Outcome is the result of the program run with a patch.
+type Outcome struct {
+    Patch       Patch
+    ExitCode    ExitCode
+    Accumulator int
+    Steps       int
+}
+
+// RunPatches runs the program once with each of the patches. Runs are spread among given number of goroutines, all of
+// them sharing the single program. Outcomes are returned in order of the patches. Patch of the config is replaced by
+// the individual patches. Once the context is done, all the remaining runs end with Cancelled exit code.
+func RunPatches(ctx context.Context, program *Program, patches []Patch, config Config, workers int) []Outcome {
+    if workers < 1 {
+        workers = 1
+    }
+
+    outcomes := make([]Outcome, len(patches))
+    indexes := make(chan int)
+    var wg sync.WaitGroup
+    for w := 0; w < workers; w++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for i := range indexes {
+                config := config
+                config.Patch = patches[i]
+                machine := New(program, config)
+                exitCode := machine.RunContext(ctx)
+                outcomes[i] = Outcome{
+                    Patch:       patches[i],
+                    ExitCode:    exitCode,
+                    Accumulator: machine.Accumulator(),
+                    Steps:       machine.Steps(),
+                }
+            }
+        }()
+    }
+
+    for i := range patches {
+        indexes <- i
+    }
+    close(indexes)
+    wg.Wait()
+    return outcomes
+}
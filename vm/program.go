@@ -0,0 +1,104 @@
+package vm
+
+import (
+    "fmt"
+    "io"
+    "strconv"
+    "strings"
+
+    "AdventOfCode20/input"
+)
+
+// Instruction is a single operation of the program together with its argument.
+type Instruction struct {
+    Op       *Opcode
+    Argument int
+}
+
+func (i Instruction) String() string {
+    return fmt.Sprintf("%s %+d", i.Op.Name, i.Argument)
+}
+
+// Successors lists indexes of instructions which can follow the instruction on given index.
+func (i Instruction) Successors(index int) []int {
+    return i.Op.Successors(index, i.Argument)
+}
+
+// Program is the sequence of instructions of the console. Machines never modify the program they run, so a single
+// program can be shared by many of them.
+type Program struct {
+    Instructions []Instruction
+    Set          *InstructionSet
+}
+
+// Len returns the number of instructions. Program terminates successfully by moving its pointer to this index.
+func (p *Program) Len() int {
+    return len(p.Instructions)
+}
+
+// Clone creates a copy of the program, whose instructions can be changed independently.
+func (p *Program) Clone() *Program {
+    return &Program{
+        Instructions: append([]Instruction(nil), p.Instructions...),
+        Set:          p.Set,
+    }
+}
+
+// String returns the source code of the program, one instruction per line.
+func (p *Program) String() string {
+    var sb strings.Builder
+    for _, inst := range p.Instructions {
+        sb.WriteString(inst.String())
+        sb.WriteByte('\n')
+    }
+    return sb.String()
+}
+
+// Assemble parses the source code of the program, where every line holds one instruction ("jmp -3"). Opcodes are
+// looked up in given instruction set. Invalid lines are passed to the error handler, blank lines are ignored.
+func Assemble(reader io.Reader, set *InstructionSet, onError input.ErrorHandler) (*Program, error) {
+    program := &Program{Set: set}
+    err := input.EachLine(reader, func(line input.Line) error {
+        if strings.TrimSpace(line.Text) == "" {
+            return nil
+        }
+
+        inst, err := ParseInstruction(line, set)
+        if err != nil {
+            return onError(err)
+        }
+        program.Instructions = append(program.Instructions, inst)
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    return program, nil
+}
+
+// ParseInstruction parses the single line of source code. Argument is a signed integer, the sign is mandatory.
+func ParseInstruction(line input.Line, set *InstructionSet) (Instruction, error) {
+    text := strings.TrimRight(line.Text, " \t")
+    indent := len(line.Text) - len(strings.TrimLeft(line.Text, " \t"))
+    fields := strings.Fields(text)
+    if len(fields) != 2 {
+        return Instruction{}, input.Errorf(line, indent+1, "expected opcode and argument, got %q", strings.TrimSpace(text))
+    }
+
+    op, ok := set.Lookup(fields[0])
+    if !ok {
+        return Instruction{}, input.Errorf(line, indent+1, "unknown opcode %q", fields[0])
+    }
+
+    argumentColumn := strings.Index(line.Text[indent+len(fields[0]):], fields[1]) + indent + len(fields[0]) + 1
+    if fields[1][0] != '+' && fields[1][0] != '-' {
+        return Instruction{}, input.Errorf(line, argumentColumn, "argument %q has to start with sign", fields[1])
+    }
+    argument, err := strconv.Atoi(fields[1])
+    if err != nil {
+        return Instruction{}, input.Errorf(line, argumentColumn, "invalid argument %q", fields[1])
+    }
+
+    return Instruction{Op: op, Argument: argument}, nil
+}
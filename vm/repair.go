@@ -0,0 +1,158 @@
+package vm
+
+import (
+    "errors"
+    "fmt"
+)
+
+// ErrNoRepair is returned when no allowed combination of edits makes the program terminate.
+var ErrNoRepair = errors.New("no repair found")
+
+// Substitution allows the repair to replace opcode of an instruction, keeping its argument.
+type Substitution struct {
+    From *Opcode
+    To   *Opcode
+}
+
+// HandheldSubstitutions are the repairs of the original puzzle: "jmp" and "nop" can be swapped.
+func HandheldSubstitutions() []Substitution {
+    return []Substitution{{From: Jump, To: NoOperation}, {From: NoOperation, To: Jump}}
+}
+
+// RepairOptions configure the search of the program repair.
+type RepairOptions struct {
+    Substitutions []Substitution
+    // MaxEdits limits the number of changed instructions, zero means no limit.
+    MaxEdits int
+}
+
+// Edit is a single change of the program made by the repair.
+type Edit struct {
+    Index int
+    From  Instruction
+    To    Instruction
+}
+
+func (e Edit) String() string {
+    return fmt.Sprintf("%d: %v -> %v", e.Index, e.From, e.To)
+}
+
+// Repair is the result of successful repair search.
+type Repair struct {
+    // Edits in order of their execution.
+    Edits []Edit
+    // Patch holds all the edits, it can be applied to the original program.
+    Patch Patch
+    // Program is the patched copy of the original program.
+    Program *Program
+    // Accumulator holds the value of the accumulator after the patched program terminates.
+    Accumulator int
+}
+
+// Predecessor of the node on the cheapest path found so far.
+type repairStep struct {
+    from int
+    edit *Edit
+}
+
+// FindRepair searches for the minimal set of edits making the program terminate successfully. The control-flow graph
+// is built only once; the search then walks it as a graph whose edges following the original instructions are free
+// and edges of substituted instructions cost one edit. As soon as the search reaches an instruction from which the
+// original program terminates, no more edits are needed.
+//
+// A terminating run never executes any instruction twice, so every instruction on the found path is patched at most
+// once and the patched program follows the path exactly. That only holds for opcodes whose control flow does not
+// depend on the registers, so the patched program is always run to confirm the repair.
+func FindRepair(program *Program, options RepairOptions) (*Repair, error) {
+    graph := BuildGraph(program)
+    terminating := graph.Terminating()
+
+    substitutes := make(map[*Opcode][]*Opcode)
+    for _, s := range options.Substitutions {
+        substitutes[s.From] = append(substitutes[s.From], s.To)
+    }
+
+    // 0-1 breadth-first search: nodes are settled level by level, the level being their number of edits. Free edges
+    // keep the node in the current level, edited ones move it to the next one. Order within a level does not matter, so
+    // both levels are plain stacks and every push and pop takes constant time.
+    edits := make([]int, graph.End()+1)
+    steps := make([]*repairStep, graph.End()+1)
+    settled := make([]bool, graph.End()+1)
+    for i := range edits {
+        edits[i] = -1
+    }
+    edits[0] = 0
+    current, next := []int{0}, []int(nil)
+
+    relax := func(from, to int, edit *Edit) {
+        if !graph.Contains(to) || settled[to] {
+            return
+        }
+        cost := edits[from]
+        if edit != nil {
+            cost++
+            if options.MaxEdits > 0 && cost > options.MaxEdits {
+                return
+            }
+        }
+        if edits[to] != -1 && edits[to] <= cost {
+            return
+        }
+
+        edits[to] = cost
+        steps[to] = &repairStep{from: from, edit: edit}
+        if edit == nil {
+            current = append(current, to)
+        } else {
+            next = append(next, to)
+        }
+    }
+
+    target := -1
+    for len(current) > 0 || len(next) > 0 {
+        if len(current) == 0 {
+            current, next = next, nil
+        }
+        node := current[len(current)-1]
+        current = current[:len(current)-1]
+        if settled[node] {
+            continue
+        }
+        settled[node] = true
+        if terminating[node] {
+            target = node
+            break
+        }
+
+        inst := program.Instructions[node]
+        for _, next := range graph.Successors(node) {
+            relax(node, next, nil)
+        }
+        for _, op := range substitutes[inst.Op] {
+            patched := Instruction{Op: op, Argument: inst.Argument}
+            edit := &Edit{Index: node, From: inst, To: patched}
+            for _, next := range patched.Successors(node) {
+                relax(node, next, edit)
+            }
+        }
+    }
+    if target == -1 {
+        return nil, ErrNoRepair
+    }
+
+    repair := &Repair{Patch: make(Patch)}
+    for node := target; steps[node] != nil; node = steps[node].from {
+        if edit := steps[node].edit; edit != nil {
+            repair.Edits = append([]Edit{*edit}, repair.Edits...)
+            repair.Patch[edit.Index] = edit.To
+        }
+    }
+    repair.Program = repair.Patch.Apply(program)
+
+    machine := New(program, Config{Patch: repair.Patch})
+    if exitCode := machine.Run(); exitCode != Success {
+        return nil, fmt.Errorf("%w: patched program ends with %v", ErrNoRepair, exitCode)
+    }
+    repair.Accumulator = machine.Accumulator()
+    return repair, nil
+}
@@ -0,0 +1,266 @@
+package vm
+
+import (
+    "context"
+    "errors"
+    "reflect"
+    "strings"
+    "testing"
+    "time"
+
+    "AdventOfCode20/input"
+)
+
+const example = `nop +0
+acc +1
+jmp +4
+acc +3
+jmp -3
+acc -99
+acc +1
+jmp -4
+acc +6
+`
+
+func assemble(t *testing.T, source string, set *InstructionSet) *Program {
+    t.Helper()
+    program, err := Assemble(strings.NewReader(source), set, input.FailOnError)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return program
+}
+
+func TestAssembleReportsPosition(t *testing.T) {
+    for _, tc := range []struct {
+        source string
+        line   int
+        column int
+    }{
+        {"nop +0\nmul +2\n", 2, 1},
+        {"nop +0\nacc +1\n  jmp 3\n", 3, 7},
+        {"acc\n", 1, 1},
+        {"acc +x\n", 1, 5},
+    } {
+        _, err := Assemble(strings.NewReader(tc.source), Handheld(), input.FailOnError)
+        var inputErr *input.Error
+        if !errors.As(err, &inputErr) || inputErr.Line != tc.line || inputErr.Column != tc.column {
+            t.Errorf("%q: expected error at %d:%d, got %v", tc.source, tc.line, tc.column, err)
+        }
+    }
+}
+
+func TestRunDetectsLoop(t *testing.T) {
+    program := assemble(t, example, Handheld())
+    machine := New(program, Config{Trace: true})
+    if exitCode := machine.Run(); exitCode != InfiniteLoop || machine.Accumulator() != 5 {
+        t.Errorf("got %v with accumulator %d, want infinite loop with 5", exitCode, machine.Accumulator())
+    }
+    if len(machine.Trace()) != machine.Steps() || machine.ExecCount(1) != 1 || machine.ExecCount(5) != 0 {
+        t.Errorf("unexpected trace of %d entries after %d steps", len(machine.Trace()), machine.Steps())
+    }
+
+    // Machine only reads the program, so another run starts from scratch.
+    program.Instructions[7].Op = NoOperation
+    machine.Reset()
+    if exitCode := machine.Run(); exitCode != Success || machine.Accumulator() != 8 {
+        t.Errorf("got %v with accumulator %d, want success with 8", exitCode, machine.Accumulator())
+    }
+}
+
+func TestExtendedOpcodeWithStateLoopDetection(t *testing.T) {
+    // Jumps by its argument unless the accumulator is zero; revisiting an instruction is no longer a loop.
+    jumpIfNotZero := &Opcode{
+        Name: "jnz",
+        Execute: func(r *Registers, argument int) {
+            if r.Accumulator != 0 {
+                r.Pointer += argument
+            } else {
+                r.Pointer++
+            }
+        },
+        Successors: func(index, argument int) []int {
+            return []int{index + 1, index + argument}
+        },
+    }
+    set := Handheld()
+    if err := set.Define(jumpIfNotZero); err != nil {
+        t.Fatal(err)
+    }
+    if err := set.Define(jumpIfNotZero); err == nil {
+        t.Error("expected error of duplicate opcode")
+    }
+
+    program := assemble(t, "acc +3\nacc -1\njnz -1\n", set)
+    if exitCode := New(program, Config{}).Run(); exitCode != InfiniteLoop {
+        t.Errorf("revisit detection: got %v, want infinite loop", exitCode)
+    }
+    machine := New(program, Config{LoopDetection: DetectRepeatedState})
+    if exitCode := machine.Run(); exitCode != Success || machine.Steps() != 7 {
+        t.Errorf("state detection: got %v after %d steps, want success after 7", exitCode, machine.Steps())
+    }
+
+    program = assemble(t, "acc +1\njnz +0\n", set)
+    if exitCode := New(program, Config{LoopDetection: DetectRepeatedState}).Run(); exitCode != InfiniteLoop {
+        t.Errorf("state detection: got %v, want infinite loop", exitCode)
+    }
+}
+
+func TestFindRepair(t *testing.T) {
+    repair, err := FindRepair(assemble(t, example, Handheld()), RepairOptions{Substitutions: HandheldSubstitutions()})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(repair.Edits) != 1 || repair.Edits[0].Index != 7 || repair.Accumulator != 8 {
+        t.Errorf("got edits %v with accumulator %d, want single edit of 7 with 8", repair.Edits, repair.Accumulator)
+    }
+
+    // Both jumps have to be changed, which is more than the puzzle itself allows.
+    program := assemble(t, "acc +2\njmp +0\nacc +3\njmp -2\nacc +5\n", Handheld())
+    options := RepairOptions{Substitutions: HandheldSubstitutions(), MaxEdits: 1}
+    if _, err := FindRepair(program, options); !errors.Is(err, ErrNoRepair) {
+        t.Errorf("expected no repair with single edit, got %v", err)
+    }
+    options.MaxEdits = 0
+    repair, err = FindRepair(program, options)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(repair.Edits) != 2 || repair.Edits[0].Index != 1 || repair.Edits[1].Index != 3 || repair.Accumulator != 10 {
+        t.Errorf("got edits %v with accumulator %d, want edits of 1 and 3 with 10", repair.Edits, repair.Accumulator)
+    }
+    if program.Instructions[1].Op != Jump {
+        t.Error("repair modified the original program")
+    }
+
+    terminating := BuildGraph(program).Terminating()
+    expected := []bool{false, false, false, false, true, true}
+    if !reflect.DeepEqual(terminating, expected) {
+        t.Errorf("got terminating %v, want %v", terminating, expected)
+    }
+}
+
+func TestStepBack(t *testing.T) {
+    machine := New(assemble(t, example, Handheld()), Config{Trace: true})
+    if machine.StepBack() {
+        t.Error("expected no instruction to revert on fresh machine")
+    }
+
+    machine.Run()
+    for machine.Steps() > 3 {
+        machine.StepBack()
+    }
+    expected := Registers{Accumulator: 1, Pointer: 6}
+    if machine.Registers() != expected || machine.ExitCode() != Running || machine.ExecCount(1) != 1 || machine.ExecCount(4) != 0 {
+        t.Errorf("got registers %+v (%v) after reverting to step 3, want %+v", machine.Registers(), machine.ExitCode(), expected)
+    }
+}
+
+func TestBlocksAndExport(t *testing.T) {
+    graph := BuildGraph(assemble(t, example, Handheld()))
+    expected := []Block{{0, 1}, {1, 3}, {3, 5}, {5, 6}, {6, 8}, {8, 9}}
+    if blocks := graph.Blocks(); !reflect.DeepEqual(blocks, expected) {
+        t.Errorf("got blocks %v, want %v", blocks, expected)
+    }
+
+    machine := New(graph.Program(), Config{Trace: true})
+    machine.Run()
+    h, err := HighlightRun(machine)
+    if err != nil {
+        t.Fatal(err)
+    }
+    var sb strings.Builder
+    if err := graph.WriteDOT(&sb, h); err != nil {
+        t.Fatal(err)
+    }
+    for _, line := range []string{
+        `b1 [label="1: acc +1\l2: jmp +4\l", style=filled, fillcolor=lightcoral];`,
+        `b0 -> b1 [style=dashed, color=blue, penwidth=2];`,
+        `b6 -> b3 [color=red, penwidth=2];`,
+        `b8 -> exit [style=dashed];`,
+    } {
+        if !strings.Contains(sb.String(), line) {
+            t.Errorf("DOT output does not contain %q:\n%s", line, sb.String())
+        }
+    }
+}
+
+func TestAnalyze(t *testing.T) {
+    analysis := Analyze(assemble(t, "acc +1\njmp +0\nnop +0\njmp +6\njmp -1\nacc +2\nacc -5\njmp -2\n", Handheld()))
+    if analysis.Terminates {
+        t.Error("expected program which never terminates")
+    }
+    if expected := []int{2, 3, 4, 5, 6, 7}; !reflect.DeepEqual(analysis.Unreachable, expected) {
+        t.Errorf("got unreachable %v, want %v", analysis.Unreachable, expected)
+    }
+    if expected := []int{3}; !reflect.DeepEqual(analysis.OutOfRange, expected) {
+        t.Errorf("got out of range %v, want %v", analysis.OutOfRange, expected)
+    }
+    if expected := [][]int{{1}, {5, 6, 7}}; !reflect.DeepEqual(analysis.Loops, expected) {
+        t.Errorf("got loops %v, want %v", analysis.Loops, expected)
+    }
+    if block := analysis.Blocks[len(analysis.Blocks)-1]; block.Start != 5 || !block.Constant || block.Accumulator != -3 {
+        t.Errorf("got last block %+v, want block from 5 with accumulator -3", block)
+    }
+
+    labels := analysis.Labels()
+    if line := analysis.Disassemble(7, labels); line != "jmp L5" {
+        t.Errorf("got %q, want \"jmp L5\"", line)
+    }
+    if line := analysis.Disassemble(3, labels); line != "jmp +6" {
+        t.Errorf("got %q, want \"jmp +6\"", line)
+    }
+}
+
+func TestRunPatchesInParallel(t *testing.T) {
+    program := assemble(t, example, Handheld())
+    source := program.String()
+    patches := SingleSubstitutions(program, HandheldSubstitutions())
+    if len(patches) != 4 {
+        t.Fatalf("got %d patches, want 4", len(patches))
+    }
+
+    outcomes := RunPatches(context.Background(), program, patches, Config{}, 3)
+    var succeeded []int
+    for _, outcome := range outcomes {
+        if outcome.ExitCode == Success {
+            succeeded = append(succeeded, outcome.Patch.Indexes()...)
+            if outcome.Accumulator != 8 {
+                t.Errorf("got accumulator %d, want 8", outcome.Accumulator)
+            }
+        }
+    }
+    if !reflect.DeepEqual(succeeded, []int{7}) {
+        t.Errorf("got successful patches of %v, want [7]", succeeded)
+    }
+    if program.String() != source {
+        t.Error("patched runs modified the shared program")
+    }
+}
+
+func TestBoundedExecution(t *testing.T) {
+    // Accumulator grows forever, so the state never repeats.
+    program := assemble(t, "acc +1\njmp -1\n", Handheld())
+    machine := New(program, Config{LoopDetection: DetectRepeatedState, MaxSteps: 100})
+    if exitCode := machine.Run(); exitCode != StepLimitExceeded || machine.Steps() != 100 {
+        t.Errorf("got %v after %d steps, want step limit exceeded after 100", exitCode, machine.Steps())
+    }
+
+    ctx, cancel := context.WithCancel(context.Background())
+    cancel()
+    machine = New(program, Config{LoopDetection: DetectNone})
+    if exitCode := machine.RunContext(ctx); exitCode != Cancelled {
+        t.Errorf("got %v, want cancelled", exitCode)
+    }
+    if exitCode := machine.Step(); exitCode != Running || machine.Steps() != 1 {
+        t.Errorf("got %v after %d steps, want cancelled machine to continue", exitCode, machine.Steps())
+    }
+
+    ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+    defer cancel()
+    patches := []Patch{{1: {Op: NoOperation, Argument: -1}}, nil}
+    outcomes := RunPatches(ctx, program, patches, Config{LoopDetection: DetectNone}, 1)
+    if outcomes[0].ExitCode != Success || outcomes[1].ExitCode != Cancelled {
+        t.Errorf("got %v and %v, want success and cancelled", outcomes[0].ExitCode, outcomes[1].ExitCode)
+    }
+}